@@ -12,15 +12,33 @@ import (
 
 type Compiler struct {
 	Instructions cpu.OpcodeSet
+	// the constants conditional blocks can check
+	Defines map[string]int
 }
 
 type CompilationError struct {
+	// Code is one of the Code constants, for the kind of error this is
+	Code     string
 	Message  string
 	Location lexer.Position
+	// where the code the error is about ends, if it's known
+	End lexer.Position
+	// other places the error has to do with, like where a duplicate symbol was first declared
+	Related []Related
+}
+
+// Related is another place that a CompilationError has to do with
+type Related struct {
+	Message       string
+	Location, End lexer.Position
 }
 
 func (e *CompilationError) String() string {
-	return fmt.Sprintf("%s: %s", e.Location, e.Message)
+	s := fmt.Sprintf("%s: %s", e.Location, e.Message)
+	for _, r := range e.Related {
+		s += fmt.Sprintf("\n\t%s at %s", r.Message, r.Location)
+	}
+	return s
 }
 
 func (c *Compiler) Compile(f *parser.File) (*linker.Object, []CompilationError) {
@@ -28,17 +46,77 @@ func (c *Compiler) Compile(f *parser.File) (*linker.Object, []CompilationError)
 	fragments := map[string]*linker.Fragment{}
 	env := NewRootEnvironment()
 
-	for _, it := range f.Fragment {
+	instructions := c.Instructions
+	if f.CPU != nil {
+		required, ok := cpu.Targets[strings.ToLower(f.CPU.Name)]
+		if !ok {
+			errors = append(errors, CompilationError{Code: CodeUnknownCPU, Message: fmt.Sprintf("Unknown CPU '%s', expected one of %s", f.CPU.Name, strings.Join(cpu.TargetNames(), ", ")), Location: f.CPU.Pos, End: f.CPU.EndPos})
+		} else if !c.Instructions.Includes(required) {
+			errors = append(errors, CompilationError{Code: CodeUnsupportedCPU, Message: fmt.Sprintf("The file is written for the %s, which the target CPU cannot run code for", f.CPU.Name), Location: f.CPU.Pos, End: f.CPU.EndPos})
+		} else {
+			// so that the file can only use what its CPU has
+			instructions = required
+		}
+	}
+
+	// conditional blocks are decided first, leaving only what they chose
+	for name, value := range c.Defines {
+		env.Bind(name, &Constant{MyName: name, Value: value})
+	}
+	declarations, errs := chosenDeclarations(env, f.Declarations, map[*parser.File]bool{f: true})
+	errors = append(errors, errs...)
+	for i := range declarations.Fragment {
+		declarations.Fragment[i].Statements, errs = chosenStatements(env, declarations.Fragment[i].Statements)
+		errors = append(errors, errs...)
+	}
+	for i := range declarations.Macros {
+		declarations.Macros[i].Statements, errs = chosenStatements(env, declarations.Macros[i].Statements)
+		errors = append(errors, errs...)
+	}
+
+	macros := map[string]parser.Macro{}
+	for _, it := range declarations.Macros {
+		if first, ok := macros[it.Name]; ok {
+			errors = append(errors, CompilationError{
+				Code:     CodeDuplicateMacro,
+				Message:  fmt.Sprintf("Duplicate macro '%s'", it.Name),
+				Location: it.Pos,
+				End:      it.EndPos,
+				Related:  []Related{{Message: "first declared", Location: first.Pos, End: first.EndPos}},
+			})
+			continue
+		}
+		parameters := map[string]parser.Parameter{}
+		for _, p := range it.Parameters {
+			if first, ok := parameters[p.Name]; ok {
+				errors = append(errors, CompilationError{
+					Code:     CodeDuplicateParameter,
+					Message:  fmt.Sprintf("Duplicate parameter '%s' in the macro '%s'", p.Name, it.Name),
+					Location: p.Pos,
+					End:      p.EndPos,
+					Related:  []Related{{Message: "first declared", Location: first.Pos, End: first.EndPos}},
+				})
+				continue
+			}
+			parameters[p.Name] = p
+		}
+		macros[it.Name] = it
+	}
+
+	for _, it := range declarations.Fragment {
 		fragmentEnv := env.NewSymbol(it.Name)
+		fragmentEnv.Pos, fragmentEnv.EndPos = it.Pos, it.EndPos
 		if !env.Bind(it.Name, fragmentEnv) {
-			errors = append(errors, CompilationError{fmt.Sprintf("Duplicate symbol '%s'", it.Name), it.Pos})
+			errors = append(errors, duplicate(env, it.Name, it.Pos, it.EndPos))
 		}
 		for _, s := range it.Statements {
 			switch s := s.(type) {
-			case parser.OpcodeInvocation:
+			case parser.OpcodeInvocation, parser.MacroInvocation, parser.Data, parser.Repeat:
 			case parser.SymbolDeclaration:
-				if !fragmentEnv.Bind(s.Name, fragmentEnv.NewSubsymbol(s.Name)) {
-					errors = append(errors, CompilationError{fmt.Sprintf("Duplicate symbol '%s'", s.Name), it.Pos})
+				subsymbol := fragmentEnv.NewSubsymbol(s.Name)
+				subsymbol.Pos, subsymbol.EndPos = s.Pos, s.EndPos
+				if !fragmentEnv.Bind(s.Name, subsymbol) {
+					errors = append(errors, duplicate(&fragmentEnv.Environment, s.Name, s.Pos, s.EndPos))
 				}
 			default:
 				panic("unhandled case")
@@ -46,145 +124,362 @@ func (c *Compiler) Compile(f *parser.File) (*linker.Object, []CompilationError)
 		}
 	}
 
-	for _, it := range f.Fragment {
-		expressions := []*linker.Expression{}
+	for _, it := range declarations.ZeroPage {
+		fragmentEnv := env.NewSymbol(it.Name)
+		fragmentEnv.Pos, fragmentEnv.EndPos = it.Pos, it.EndPos
+		if !env.Bind(it.Name, fragmentEnv) {
+			errors = append(errors, duplicate(env, it.Name, it.Pos, it.EndPos))
+		}
+		fragments[it.Name] = &linker.Fragment{
+			Symbol:  GlobalName(fragmentEnv),
+			Span:    spanOf(it.Pos, it.EndPos),
+			Segment: linker.Segment_ZERO_PAGE,
+			Reserve: uint32(it.Size),
+		}
+	}
+
+	for _, it := range declarations.Fragment {
 		fragmentEnvObj, _ := env.Lookup(it.Name)
 		fragmentEnv := fragmentEnvObj.(*Symbol)
 
+		fc := &fragmentCompiler{
+			instructions: instructions,
+			macros:       macros,
+			root:         env,
+			expressions:  []*linker.Expression{},
+		}
 		for _, s := range it.Statements {
-			switch s := s.(type) {
-			case parser.OpcodeInvocation:
-				opcode, ok := cpu.OpcodeNames[strings.ToLower(s.Opcode)]
-				if !ok {
-					errors = append(errors, CompilationError{fmt.Sprintf("Invalid opcode '%s'", s.Opcode), s.Pos})
-					continue
-				}
-				opcodes := c.Instructions.Find(opcode)
-				if len(opcodes) == 0 {
-					errors = append(errors, CompilationError{fmt.Sprintf("Opcode '%s' does not exist on the architecture", s.Opcode), s.Pos})
-					continue
-				}
-				resolved, ok := opcodes.FindOne(opcode, s.Address.AddressingMode())
-				if !ok {
-					errors = append(errors, CompilationError{fmt.Sprintf("Opcode '%s' cannot be used with %s addressing", s.Opcode, s.Address.AddressingMode()), s.Pos})
-				}
-				_ = resolved
+			fc.statement(s, fragmentEnv)
+		}
+		errors = append(errors, fc.errors...)
 
-				expressions = append(expressions, &linker.Expression{
-					Inner: &linker.Expression_Literal_{
-						Literal: &linker.Expression_Literal{
-							Value: []byte{resolved.Hex},
-						},
-					},
-				})
+		fragments[it.Name] = &linker.Fragment{
+			Expressions: fc.expressions,
+			Symbol:      GlobalName(fragmentEnv),
+			Span:        spanOf(it.Pos, it.EndPos),
+		}
+	}
 
-				var expr parser.Expression
-				var size linker.SymbolSize
-
-				switch addr := s.Address.(type) {
-				case parser.AddrImmediate:
-					expr = addr.Value
-					size = linker.SymbolSize_BYTE
-				case parser.AddrAbsoluteIndirect:
-					expr = addr.Address
-					size = linker.SymbolSize_WORD
-				case parser.AddrAbsoluteIndexedX:
-					expr = addr.Address
-					size = linker.SymbolSize_WORD
-				case parser.AddrAbsoluteIndexedY:
-					expr = addr.Address
-					size = linker.SymbolSize_WORD
-				case parser.AddrAbsoluteAddress:
-					expr = addr.Address
-					size = linker.SymbolSize_WORD
-				case parser.AddrZeroPageXIndexedIndirect:
-					expr = addr.Address
-					size = linker.SymbolSize_BYTE
-				case parser.AddrZeroPageIndirectYIndex:
-					expr = addr.Address
-					size = linker.SymbolSize_BYTE
-				case parser.AddrZeroPageXIndexed:
-					expr = addr.Address
-					size = linker.SymbolSize_BYTE
-				case parser.AddrZeroPageYIndexed:
-					expr = addr.Address
-					size = linker.SymbolSize_BYTE
-				case parser.AddrZeroPage:
-					expr = addr.Address
-					size = linker.SymbolSize_BYTE
-				case parser.AddrRelative:
-					expr = addr.Address
-					size = linker.SymbolSize_RELATIVE
-				case parser.AddrAccumulator:
-					expr = nil
-				case parser.AddrImplied:
-					expr = nil
-				}
+	total := &linker.Object{}
+	total.Fragments = fragments
+	for _, it := range declarations.Tests {
+		test, errs := compileTest(env, it)
+		errors = append(errors, errs...)
+		total.Tests = append(total.Tests, test)
+	}
+	return total, errors
+}
 
-				if expr == nil {
-					continue
-				}
+// compiles the statements of a fragment one at a time, following
+// register widths in a straight line through it
+type fragmentCompiler struct {
+	instructions cpu.OpcodeSet
+	macros       map[string]parser.Macro
+	root         *Environment
+	widths       cpu.RegisterWidths
+	expressions  []*linker.Expression
+	errors       []CompilationError
 
-				// fmt.Printf("%#v\n", expr)
-				switch e := expr.(type) {
-				case parser.NumericLiteral:
-					// TODO: check that it fits into the size
-					// errors = append(errors, CompilationError{"Unhandled numeric literal", e.Pos})
-					var numericBytes []byte
-					switch size {
-					case linker.SymbolSize_WORD:
-						numericBytes = []byte{byte(e.Number), byte(e.Number >> 8)}
-					case linker.SymbolSize_BYTE, linker.SymbolSize_RELATIVE:
-						numericBytes = []byte{byte(e.Number)}
-					}
-					expressions = append(expressions, &linker.Expression{
-						Inner: &linker.Expression_Literal_{
-							Literal: &linker.Expression_Literal{
-								Value: numericBytes,
-							},
-						},
-					})
-				case parser.Symbol:
-					o, ok := fragmentEnv.Lookup(e.Name)
-					if !ok {
-						errors = append(errors, CompilationError{fmt.Sprintf("Symbol not found: '%s'", e.Name), e.Pos})
-						continue
-					}
-					if _, ok := o.(Symbollike); !ok {
-						errors = append(errors, CompilationError{fmt.Sprintf("'%s' is not a symbol", e.Name), e.Pos})
-						continue
+	// how many macros and repeat blocks have been expanded, and how many
+	// of the macros are still being expanded
+	expansions int
+	depth      int
+}
+
+// env is where the statement's symbols are looked up, which is the
+// fragment's unless the statement came from a macro or a repeat block
+func (fc *fragmentCompiler) statement(s parser.Statement, env *Symbol) {
+	switch s := s.(type) {
+	case parser.OpcodeInvocation:
+		opcode, ok := cpu.OpcodeNames[strings.ToLower(s.Opcode)]
+		if !ok {
+			fc.errors = append(fc.errors, CompilationError{Code: CodeInvalidOpcode, Message: fmt.Sprintf("Invalid opcode '%s'", s.Opcode), Location: s.Pos, End: s.EndPos})
+			return
+		}
+		opcodes := fc.instructions.Find(opcode)
+		if len(opcodes) == 0 {
+			fc.errors = append(fc.errors, CompilationError{Code: CodeUnsupportedOpcode, Message: fmt.Sprintf("Opcode '%s' does not exist on the architecture", s.Opcode), Location: s.Pos, End: s.EndPos})
+			return
+		}
+		mode := s.Address.AddressingMode()
+		if addr, ok := s.Address.(parser.AddrAutomatic); ok {
+			zeroPage, zeroPageOk := opcodes.FindOne(opcode, addr.ZeroPageMode())
+			absolute, absoluteOk := opcodes.FindOne(opcode, mode)
+			switch {
+			case zeroPageOk && absoluteOk:
+				n, ok, err := constant(env, addr.Address)
+				if err != nil {
+					fc.errors = append(fc.errors, *err)
+					return
+				}
+				if ok {
+					if n >= 0 && n <= 0xFF {
+						mode = zeroPage.Mode
 					}
-					symbol, _ := o.(Symbollike)
-					expressions = append(expressions, &linker.Expression{
-						Inner: &linker.Expression_Symbol_{
-							Symbol: &linker.Expression_Symbol{
-								Name: GlobalName(symbol),
-								Size: size,
-							},
-						},
-					})
+					break
 				}
-			case parser.SymbolDeclaration:
-				sym, _ := fragmentEnv.Lookup(s.Name)
-				subsymbol := sym.(*Subsymbol)
-				expressions = append(expressions, &linker.Expression{
-					Inner: &linker.Expression_Subsymbol_{
-						Subsymbol: &linker.Expression_Subsymbol{
-							Name: GlobalName(subsymbol),
+				// the linker decides once it knows where the symbol is
+				expr, err := compileOperand(env, addr.Address, linker.SymbolSize_WORD)
+				if err != nil {
+					fc.errors = append(fc.errors, *err)
+					return
+				}
+				fc.expressions = append(fc.expressions, &linker.Expression{
+					Inner: &linker.Expression_ZeroPageChoice_{
+						ZeroPageChoice: &linker.Expression_ZeroPageChoice{
+							Name:     expr.GetSymbol().Name,
+							ZeroPage: uint32(zeroPage.Hex),
+							Absolute: uint32(absolute.Hex),
 						},
 					},
+					Span: spanOf(s.Pos, s.EndPos),
 				})
-			default:
-				panic("unhandled case")
+				return
+			case zeroPageOk:
+				mode = zeroPage.Mode
 			}
 		}
 
-		fragments[it.Name] = &linker.Fragment{
-			Expressions: expressions,
+		resolved, ok := opcodes.FindOne(opcode, mode)
+		if !ok && mode == cpu.Relative {
+			// long branches are written the same way as short ones
+			resolved, ok = opcodes.FindOne(opcode, cpu.RelativeLong)
+		}
+		if !ok {
+			fc.errors = append(fc.errors, CompilationError{Code: CodeInvalidAddressingMode, Message: fmt.Sprintf("Opcode '%s' cannot be used with %s addressing", s.Opcode, mode), Location: s.Pos, End: s.EndPos})
+			return
+		}
+
+		fc.expressions = append(fc.expressions, &linker.Expression{
+			Inner: &linker.Expression_Literal_{
+				Literal: &linker.Expression_Literal{
+					Value: []byte{resolved.Hex},
+				},
+			},
+			Span: spanOf(s.Pos, s.EndPos),
+		})
+
+		var operands []operand
+
+		switch addr := s.Address.(type) {
+		case parser.AddrImmediate:
+			if fc.widths.OperandSize(resolved) == 2 {
+				operands = []operand{{addr.Value, linker.SymbolSize_WORD}}
+			} else {
+				operands = []operand{{addr.Value, linker.SymbolSize_BYTE}}
+			}
+		case parser.AddrBlockMove:
+			operands = []operand{{addr.Destination, linker.SymbolSize_BYTE}, {addr.Source, linker.SymbolSize_BYTE}}
+		case parser.AddrAbsoluteLong:
+			operands = []operand{{addr.Address, linker.SymbolSize_LONG}}
+		case parser.AddrAbsoluteLongIndexedX:
+			operands = []operand{{addr.Address, linker.SymbolSize_LONG}}
+		case parser.AddrAbsoluteIndirectLong:
+			operands = []operand{{addr.Address, linker.SymbolSize_WORD}}
+		case parser.AddrZeroPageIndirectLong:
+			operands = []operand{{addr.Address, linker.SymbolSize_BYTE}}
+		case parser.AddrZeroPageIndirectLongIndexedY:
+			operands = []operand{{addr.Address, linker.SymbolSize_BYTE}}
+		case parser.AddrStackRelative:
+			operands = []operand{{addr.Offset, linker.SymbolSize_BYTE}}
+		case parser.AddrStackRelativeIndirectIndexedY:
+			operands = []operand{{addr.Offset, linker.SymbolSize_BYTE}}
+		case parser.AddrAbsoluteIndirect:
+			operands = []operand{{addr.Address, linker.SymbolSize_WORD}}
+		case parser.AddrAbsoluteXIndexedIndirect:
+			operands = []operand{{addr.Address, linker.SymbolSize_WORD}}
+		case parser.AddrAbsoluteIndexedX:
+			operands = []operand{{addr.Address, linker.SymbolSize_WORD}}
+		case parser.AddrAbsoluteIndexedY:
+			operands = []operand{{addr.Address, linker.SymbolSize_WORD}}
+		case parser.AddrAbsoluteAddress:
+			operands = []operand{{addr.Address, linker.SymbolSize_WORD}}
+		case parser.AddrZeroPageXIndexedIndirect:
+			operands = []operand{{addr.Address, linker.SymbolSize_BYTE}}
+		case parser.AddrZeroPageIndirectYIndex:
+			operands = []operand{{addr.Address, linker.SymbolSize_BYTE}}
+		case parser.AddrZeroPageIndirect:
+			operands = []operand{{addr.Address, linker.SymbolSize_BYTE}}
+		case parser.AddrZeroPageXIndexed:
+			operands = []operand{{addr.Address, linker.SymbolSize_BYTE}}
+		case parser.AddrZeroPageYIndexed:
+			operands = []operand{{addr.Address, linker.SymbolSize_BYTE}}
+		case parser.AddrZeroPageRelative:
+			operands = []operand{{addr.Address, linker.SymbolSize_BYTE}, {addr.Target, linker.SymbolSize_RELATIVE}}
+		case parser.AddrZeroPage:
+			operands = []operand{{addr.Address, linker.SymbolSize_BYTE}}
+		case parser.AddrRelative:
+			if resolved.Mode == cpu.RelativeLong {
+				operands = []operand{{addr.Address, linker.SymbolSize_RELATIVE_LONG}}
+			} else {
+				operands = []operand{{addr.Address, linker.SymbolSize_RELATIVE}}
+			}
+		case parser.AddrAutomatic:
+			if resolved.Mode == addr.ZeroPageMode() {
+				operands = []operand{{addr.Address, linker.SymbolSize_BYTE}}
+			} else {
+				operands = []operand{{addr.Address, linker.SymbolSize_WORD}}
+			}
+		case parser.AddrAccumulator:
+		case parser.AddrImplied:
+		}
+
+		for _, op := range operands {
+			expr, err := compileOperand(env, op.expr, op.size)
+			if err != nil {
+				fc.errors = append(fc.errors, *err)
+				continue
+			}
+			fc.expressions = append(fc.expressions, expr)
+		}
+
+		if opcode == cpu.REP || opcode == cpu.SEP {
+			value, ok, err := constant(env, s.Address.(parser.AddrImmediate).Value)
+			if err != nil {
+				// already reported when the operand was compiled
+				return
+			}
+			if !ok {
+				fc.errors = append(fc.errors, CompilationError{Code: CodeUnknownRegisterWidth, Message: fmt.Sprintf("The operand of '%s' must be a number, so that register widths can be followed", s.Opcode), Location: s.Pos, End: s.EndPos})
+				return
+			}
+			fc.widths.Update(opcode, byte(value))
+		}
+	case parser.SymbolDeclaration:
+		sym, _ := env.Lookup(s.Name)
+		subsymbol := sym.(*Subsymbol)
+		fc.expressions = append(fc.expressions, &linker.Expression{
+			Inner: &linker.Expression_Subsymbol_{
+				Subsymbol: &linker.Expression_Subsymbol{
+					Name: GlobalName(subsymbol),
+				},
+			},
+			Span: spanOf(s.Pos, s.EndPos),
+		})
+	case parser.MacroInvocation:
+		fc.expand(s, env)
+	case parser.Repeat:
+		fc.repeat(s, env)
+	case parser.Data:
+		size := linker.SymbolSize_BYTE
+		if s.Size == "word" {
+			size = linker.SymbolSize_WORD
+		}
+		for _, value := range s.Values {
+			expr, err := compileOperand(env, value, size)
+			if err != nil {
+				fc.errors = append(fc.errors, *err)
+				continue
+			}
+			expr.Data = true
+			fc.expressions = append(fc.expressions, expr)
+		}
+	default:
+		panic("unhandled case")
+	}
+}
+
+type operand struct {
+	expr parser.Expression
+	size linker.SymbolSize
+}
+
+// the numbers that operands of each size can hold, where negative numbers are in two's complement.
+// Relative operands that are numbers rather than symbols are the offset to branch by.
+var operandRanges = map[linker.SymbolSize]struct {
+	name     string
+	min, max int
+}{
+	linker.SymbolSize_BYTE:          {"a byte", -0x80, 0xFF},
+	linker.SymbolSize_WORD:          {"a word", -0x8000, 0xFFFF},
+	linker.SymbolSize_LONG:          {"a long", -0x800000, 0xFFFFFF},
+	linker.SymbolSize_RELATIVE:      {"a branch offset", -0x80, 0x7F},
+	linker.SymbolSize_RELATIVE_LONG: {"a long branch offset", -0x8000, 0xFFFF},
+}
+
+// a number the way it's usually written for the 6502, in hex unless it's negative
+func formatNumber(n int) string {
+	if n < 0 {
+		return fmt.Sprint(n)
+	}
+	return fmt.Sprintf("$%X", n)
+}
+
+// compiles an operand, whose span is where it's written even if it's a macro parameter, so that all
+// the bytes of an instruction in a macro come from the macro's body rather than partly from the call
+func compileOperand(env *Symbol, expr parser.Expression, size linker.SymbolSize) (*linker.Expression, *CompilationError) {
+	span := spanOf(expr.Position(), expr.EndPosition())
+	env, expr = substitute(env, expr)
+	number, ok, err := constant(env, expr)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		if r := operandRanges[size]; number < r.min || number > r.max {
+			return nil, &CompilationError{
+				Code:     CodeOutOfRange,
+				Message:  fmt.Sprintf("The value %s does not fit in %s, which can be from %d to %s", formatNumber(number), r.name, r.min, formatNumber(r.max)),
+				Location: expr.Position(),
+				End:      expr.EndPosition(),
+			}
+		}
+		var numericBytes []byte
+		switch size {
+		case linker.SymbolSize_WORD:
+			numericBytes = []byte{byte(number), byte(number >> 8)}
+		case linker.SymbolSize_BYTE, linker.SymbolSize_RELATIVE:
+			numericBytes = []byte{byte(number)}
+		case linker.SymbolSize_LONG:
+			numericBytes = []byte{byte(number), byte(number >> 8), byte(number >> 16)}
+		case linker.SymbolSize_RELATIVE_LONG:
+			numericBytes = []byte{byte(number), byte(number >> 8)}
 		}
+		return &linker.Expression{
+			Inner: &linker.Expression_Literal_{
+				Literal: &linker.Expression_Literal{
+					Value: numericBytes,
+				},
+			},
+			Span: span,
+		}, nil
 	}
 
-	total := &linker.Object{}
-	total.Fragments = fragments
-	return total, errors
+	switch e := expr.(type) {
+	case parser.Symbol:
+		o, ok := env.Lookup(e.Name)
+		if !ok {
+			return nil, &CompilationError{Code: CodeUnknownSymbol, Message: fmt.Sprintf("Symbol not found: '%s'", e.Name), Location: e.Pos, End: e.EndPos}
+		}
+		symbol, ok := o.(Symbollike)
+		if !ok {
+			return nil, &CompilationError{Code: CodeNotASymbol, Message: fmt.Sprintf("'%s' is not a symbol", e.Name), Location: e.Pos, End: e.EndPos}
+		}
+		return &linker.Expression{
+			Inner: &linker.Expression_Symbol_{
+				Symbol: &linker.Expression_Symbol{
+					Name: GlobalName(symbol),
+					Size: size,
+				},
+			},
+			Span: span,
+		}, nil
+	default:
+		panic("unhandled case")
+	}
+}
+
+func positionOf(p lexer.Position) *linker.Position {
+	return &linker.Position{
+		Filename: p.Filename,
+		Offset:   int32(p.Offset),
+		Line:     int32(p.Line),
+		Column:   int32(p.Column),
+	}
+}
+
+func spanOf(start, end lexer.Position) *linker.Span {
+	return &linker.Span{
+		Start: positionOf(start),
+		End:   positionOf(end),
+	}
 }
@@ -0,0 +1,254 @@
+package compiler
+
+import (
+	"Sano/cpu"
+	"Sano/linker"
+	"Sano/parser"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func compileAndLink(t *testing.T, set cpu.OpcodeSet, origin uint16, source string) []byte {
+	t.Helper()
+
+	f, err := parser.Parser.ParseString("test.san", source)
+	if err != nil {
+		t.Fatalf("failed to parse: %s", err)
+	}
+	c := Compiler{Instructions: set}
+	obj, errs := c.Compile(f)
+	for _, err := range errs {
+		t.Error(err.String())
+	}
+	if len(errs) > 0 {
+		t.FailNow()
+	}
+	layout, err := linker.Link([]*linker.Object{obj}, origin)
+	if err != nil {
+		t.Fatalf("failed to link: %s", err)
+	}
+	return layout.Code
+}
+
+// compiles source for the 65C02, returning the errors
+func compileErrors(t *testing.T, source string) []CompilationError {
+	t.Helper()
+
+	f, err := parser.Parser.ParseString("test.san", source)
+	if err != nil {
+		t.Fatalf("failed to parse %q: %s", source, err)
+	}
+	c := Compiler{Instructions: cpu.WDC65C02Opcodes}
+	_, errs := c.Compile(f)
+	return errs
+}
+
+// compiles source for the 65C02, which should have exactly one error. It's
+// returned if so, and otherwise the test fails and ok is false.
+func compileError(t *testing.T, source string) (err CompilationError, ok bool) {
+	t.Helper()
+
+	errs := compileErrors(t, source)
+	if len(errs) != 1 {
+		t.Errorf("%q: expected one error, but got %d", source, len(errs))
+		return CompilationError{}, false
+	}
+	return errs[0], true
+}
+
+func TestRegisterWidthsDecideImmediateSizes(t *testing.T) {
+	code := compileAndLink(t, cpu.WDC65C816Opcodes, 0x1000, `@main {
+		lda #0x12;
+		rep #0x30;
+		lda #0x1234;
+		ldx #0x5678;
+		sep #0x20;
+		lda #0x12;
+		ldy #0x9ABC;
+	}`)
+	expected := []byte{
+		0xA9, 0x12,
+		0xC2, 0x30,
+		0xA9, 0x34, 0x12,
+		0xA2, 0x78, 0x56,
+		0xE2, 0x20,
+		0xA9, 0x12,
+		0xA0, 0xBC, 0x9A,
+	}
+	if !bytes.Equal(code, expected) {
+		t.Fatalf("got % X, expected % X", code, expected)
+	}
+}
+
+func TestLongBranches(t *testing.T) {
+	code := compileAndLink(t, cpu.WDC65C816Opcodes, 0x1000, `@main {
+		&top:
+		brl ~top;
+		jsl ==top;
+		bra ~top;
+	}`)
+	expected := []byte{
+		0x82, 0xFD, 0xFF,
+		0x22, 0x00, 0x10, 0x00,
+		0x80, 0xF7,
+	}
+	if !bytes.Equal(code, expected) {
+		t.Fatalf("got % X, expected % X", code, expected)
+	}
+}
+
+func TestCPUDirectiveRestrictsOpcodes(t *testing.T) {
+	f, err := parser.Parser.ParseString("test.san", `.cpu "6502"; @main { stz :0x12; }`)
+	if err != nil {
+		t.Fatalf("failed to parse: %s", err)
+	}
+	c := Compiler{Instructions: cpu.WDC65C02Opcodes}
+	_, errs := c.Compile(f)
+	if len(errs) != 1 {
+		t.Fatalf("expected stz to be rejected, but got %d errors", len(errs))
+	}
+}
+
+func TestCPUDirectiveMustMatchTarget(t *testing.T) {
+	f, err := parser.Parser.ParseString("test.san", `.cpu "65c816"; @main { nop !; }`)
+	if err != nil {
+		t.Fatalf("failed to parse: %s", err)
+	}
+	c := Compiler{Instructions: cpu.WDC65C02Opcodes}
+	_, errs := c.Compile(f)
+	if len(errs) != 1 {
+		t.Fatalf("expected the 65C816 file to be rejected, but got %d errors", len(errs))
+	}
+}
+
+func TestAutomaticZeroPage(t *testing.T) {
+	code := compileAndLink(t, cpu.WDC65C02Opcodes, 0x1000, `
+	@ptr zeropage 2;
+	@main {
+		lda ptr;
+		lda ptr, x;
+		lda ptr, y;
+		lda 0x12;
+		lda 0x1234;
+		jmp main;
+	}`)
+	expected := []byte{
+		0xA5, 0x22,
+		0xB5, 0x22,
+		// there's no zero page version
+		0xB9, 0x22, 0x00,
+		0xA5, 0x12,
+		0xAD, 0x34, 0x12,
+		0x4C, 0x00, 0x10,
+	}
+	if !bytes.Equal(code, expected) {
+		t.Fatalf("got % X, expected % X", code, expected)
+	}
+}
+
+func TestAutomaticZeroPageSettles(t *testing.T) {
+	// later is only in zero page once the load from data has shrunk
+	code := compileAndLink(t, cpu.WDC65C02Opcodes, 0xF8, `
+	@main {
+		lda data;
+		lda later;
+		rts !;
+	}
+	@data {
+		nop !;
+	}
+	@later {
+		nop !;
+	}`)
+	expected := []byte{
+		0xA5, 0xFD,
+		0xA5, 0xFE,
+		0x60,
+		0xEA,
+		0xEA,
+	}
+	if !bytes.Equal(code, expected) {
+		t.Fatalf("got % X, expected % X", code, expected)
+	}
+}
+
+func TestListing(t *testing.T) {
+	source := "@main {\n\tlda #0x12;\n\t&loop:\n\tjmp loop;\n}\n"
+	f, err := parser.Parser.ParseString("test.san", source)
+	if err != nil {
+		t.Fatalf("failed to parse: %s", err)
+	}
+	c := Compiler{Instructions: cpu.WDC65C02Opcodes}
+	obj, errs := c.Compile(f)
+	if len(errs) > 0 {
+		t.Fatalf("failed to compile: %s", errs[0].String())
+	}
+	layout, err := linker.Link([]*linker.Object{obj}, 0x1000)
+	if err != nil {
+		t.Fatalf("failed to link: %s", err)
+	}
+
+	var b bytes.Buffer
+	err = linker.WriteListing(&b, layout, []linker.Source{{Filename: "test.san", Text: []byte(source)}}, cpu.WDC65C02Opcodes)
+	if err != nil {
+		t.Fatalf("failed to write listing: %s", err)
+	}
+	expected := `test.san
+
+                              1  @main {
+1000  A9 12        2          2  	lda #0x12;
+                              3  	&loop:
+1002  4C 02 10     3          4  	jmp loop;
+                              5  }
+
+symbols
+
+1000  main
+1002  main/loop
+`
+	if b.String() != expected {
+		t.Fatalf("got listing:\n%s\nexpected:\n%s", b.String(), expected)
+	}
+}
+
+func TestDataIsNotDecodedAsInstructions(t *testing.T) {
+	source := "@main {\n\tlda =table;\n\trts !;\n}\n@table {\n\t.byte 0xA9, 0x01, 0x60, 0xEA;\n\tnop !;\n}\n"
+	f, err := parser.Parser.ParseString("test.san", source)
+	if err != nil {
+		t.Fatalf("failed to parse: %s", err)
+	}
+	c := Compiler{Instructions: cpu.WDC65C02Opcodes}
+	obj, errs := c.Compile(f)
+	if len(errs) > 0 {
+		t.Fatalf("failed to compile: %s", errs[0].String())
+	}
+	layout, err := linker.Link([]*linker.Object{obj}, 0x1000)
+	if err != nil {
+		t.Fatalf("failed to link: %s", err)
+	}
+
+	summaries := linker.Summarize(layout, cpu.WDC65C02Opcodes)
+	expected := []linker.Summary{
+		{Fragment: "main", Address: 0x1000, Bytes: 4, Instructions: 2, Cycles: 10, MaxCycles: 10},
+		{Fragment: "table", Address: 0x1004, Bytes: 5, Instructions: 1, Cycles: 2, MaxCycles: 2},
+	}
+	if !reflect.DeepEqual(summaries, expected) {
+		t.Fatalf("got %+v, expected %+v", summaries, expected)
+	}
+
+	var b bytes.Buffer
+	err = linker.WriteListing(&b, layout, []linker.Source{{Filename: "test.san", Text: []byte(source)}}, cpu.WDC65C02Opcodes)
+	if err != nil {
+		t.Fatalf("failed to write listing: %s", err)
+	}
+	for _, line := range []string{
+		"1004  A9 01 60 EA             6  \t.byte 0xA9, 0x01, 0x60, 0xEA;\n",
+		"1008  EA           2          7  \tnop !;\n",
+	} {
+		if !strings.Contains(b.String(), line) {
+			t.Fatalf("expected the listing to have %q, but it was:\n%s", line, b.String())
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package compiler
+
+import (
+	"Sano/parser"
+	"fmt"
+)
+
+// the declarations outside of conditional blocks, followed by the ones in included files and the
+// blocks that were chosen, where env has the constants the conditions can use. Files that have
+// already been included are in included, so that they're only declared once.
+func chosenDeclarations(env *Environment, d parser.Declarations, included map[*parser.File]bool) (parser.Declarations, []CompilationError) {
+	var errors []CompilationError
+	total := parser.Declarations{
+		Fragment: append([]parser.Fragment{}, d.Fragment...),
+		ZeroPage: append([]parser.ZeroPageFragment{}, d.ZeroPage...),
+		Macros:   append([]parser.Macro{}, d.Macros...),
+		Tests:    append([]parser.Test{}, d.Tests...),
+	}
+
+	add := func(inner parser.Declarations) {
+		total.Fragment = append(total.Fragment, inner.Fragment...)
+		total.ZeroPage = append(total.ZeroPage, inner.ZeroPage...)
+		total.Macros = append(total.Macros, inner.Macros...)
+		total.Tests = append(total.Tests, inner.Tests...)
+	}
+
+	for _, include := range d.Includes {
+		if include.Err != nil {
+			errors = append(errors, CompilationError{Code: parser.CodeInclude, Message: include.Err.Message, Location: include.Err.Pos, End: include.Err.EndPos})
+			continue
+		}
+		if include.File == nil {
+			errors = append(errors, CompilationError{Code: CodeIncludeNotLoaded, Message: fmt.Sprintf("'%s' is included, but wasn't loaded along with this file", include.Path), Location: include.Pos, End: include.EndPos})
+			continue
+		}
+		if included[include.File] {
+			continue
+		}
+		included[include.File] = true
+		inner, errs := chosenDeclarations(env, include.File.Declarations, included)
+		errors = append(errors, errs...)
+		add(inner)
+	}
+
+	for _, conditional := range d.Conditionals {
+		var chosen *parser.Declarations
+		for it := &conditional; it != nil; it = it.ElseIf {
+			value, err := evaluate(env, it.Condition)
+			if err != nil {
+				errors = append(errors, *err)
+				break
+			}
+			if value != 0 {
+				chosen = &it.Then
+				break
+			}
+			if it.Else != nil {
+				chosen = it.Else
+			}
+		}
+		if chosen == nil {
+			continue
+		}
+
+		inner, errs := chosenDeclarations(env, *chosen, included)
+		errors = append(errors, errs...)
+		add(inner)
+	}
+
+	return total, errors
+}
+
+// the statements with the conditional blocks in them replaced by the statements they chose.
+// The ones in repeat blocks are left for every iteration to choose, since they can use its index.
+func chosenStatements(env *Environment, statements []parser.Statement) ([]parser.Statement, []CompilationError) {
+	var errors []CompilationError
+	total := []parser.Statement{}
+
+	for _, s := range statements {
+		conditional, ok := s.(parser.ConditionalStatements)
+		if !ok {
+			total = append(total, s)
+			continue
+		}
+
+		var chosen []parser.Statement
+		for it := &conditional; it != nil; it = it.ElseIf {
+			value, err := evaluate(env, it.Condition)
+			if err != nil {
+				errors = append(errors, *err)
+				break
+			}
+			if value != 0 {
+				chosen = it.Then
+				break
+			}
+			chosen = it.Else
+		}
+
+		inner, errs := chosenStatements(env, chosen)
+		errors = append(errors, errs...)
+		total = append(total, inner...)
+	}
+
+	return total, errors
+}
@@ -0,0 +1,72 @@
+package compiler
+
+import (
+	"Sano/cpu"
+	"Sano/linker"
+	"Sano/parser"
+	"bytes"
+	"testing"
+)
+
+const variants = `
+.if defined(DEBUG) && DEBUG > 0 {
+	@log { lda #1; rts !; }
+} .else .if !defined(VIDEO) || VIDEO == 1 {
+	@log { lda #2; rts !; }
+} .else {
+	@log { lda #3; rts !; }
+}
+
+@main {
+	.if (VIDEO >= 2) {
+		nop !;
+	} .else .if VIDEO != 0 {
+		.if defined(DEBUG) { brk !; }
+		jsr =log;
+	}
+	rts !;
+}
+`
+
+func TestConditionalBlocks(t *testing.T) {
+	cases := []struct {
+		defines  map[string]int
+		expected []byte
+	}{
+		{map[string]int{"VIDEO": 1}, []byte{0x20, 0x04, 0x10, 0x60, 0xA9, 0x02, 0x60}},
+		{map[string]int{"VIDEO": 1, "DEBUG": 1}, []byte{0x00, 0x20, 0x05, 0x10, 0x60, 0xA9, 0x01, 0x60}},
+		{map[string]int{"VIDEO": 1, "DEBUG": 0}, []byte{0x00, 0x20, 0x05, 0x10, 0x60, 0xA9, 0x02, 0x60}},
+		{map[string]int{"VIDEO": 2}, []byte{0xEA, 0x60}},
+		{map[string]int{"VIDEO": 0}, []byte{0x60}},
+	}
+	for _, tc := range cases {
+		f, err := parser.Parser.ParseString("test.san", variants)
+		if err != nil {
+			t.Fatalf("failed to parse: %s", err)
+		}
+		c := Compiler{Instructions: cpu.WDC65C02Opcodes, Defines: tc.defines}
+		obj, errs := c.Compile(f)
+		if len(errs) > 0 {
+			t.Fatalf("%v: failed to compile: %s", tc.defines, errs[0].String())
+		}
+		layout, err := linker.Link([]*linker.Object{obj}, 0x1000)
+		if err != nil {
+			t.Fatalf("%v: failed to link: %s", tc.defines, err)
+		}
+		if !bytes.Equal(layout.Code, tc.expected) {
+			t.Errorf("%v: got % X, expected % X", tc.defines, layout.Code, tc.expected)
+		}
+	}
+}
+
+func TestUndefinedConstants(t *testing.T) {
+	f, err := parser.Parser.ParseString("test.san", variants)
+	if err != nil {
+		t.Fatalf("failed to parse: %s", err)
+	}
+	c := Compiler{Instructions: cpu.WDC65C02Opcodes, Defines: map[string]int{"DEBUG": 1}}
+	_, errs := c.Compile(f)
+	if len(errs) != 1 || errs[0].String() != "test.san:11:7: Constant 'VIDEO' is not defined" {
+		t.Errorf("got %v", errs)
+	}
+}
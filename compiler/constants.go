@@ -0,0 +1,210 @@
+package compiler
+
+import (
+	"Sano/parser"
+	"fmt"
+)
+
+// Constant is a number with a name, which is either defined for the compiler or the index of a repeat block
+type Constant struct {
+	MyName string
+	Value  int
+}
+
+func (c *Constant) Name() string {
+	return c.MyName
+}
+
+func (*Constant) Parent() (Object, bool) {
+	return nil, false
+}
+
+func (*Constant) Property(string) (Object, bool) {
+	return nil, false
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// the value of an operand if it's known while compiling, which it isn't for symbols
+func constant(env *Symbol, expr parser.Expression) (int, bool, *CompilationError) {
+	env, expr = substitute(env, expr)
+	switch e := expr.(type) {
+	case parser.NumericLiteral:
+		return e.Number, true, nil
+	case parser.Computed:
+		value, err := evaluate(&env.Environment, e.Value)
+		return value, true, err
+	case parser.Symbol:
+		if o, ok := env.Lookup(e.Name); ok {
+			if c, ok := o.(*Constant); ok {
+				return c.Value, true, nil
+			}
+		}
+		return 0, false, nil
+	default:
+		panic("unhandled case")
+	}
+}
+
+// works out a constant expression with the constants in env, stopping at the first side
+// of || and && that decides it, so that defined(NAME) can guard uses of NAME
+func evaluate(env *Environment, expr parser.ConstantExpression) (int, *CompilationError) {
+	value, err := evaluateConjunction(env, expr.Left)
+	if err != nil || len(expr.Right) == 0 {
+		return value, err
+	}
+	for _, conjunction := range expr.Right {
+		if value != 0 {
+			return 1, nil
+		}
+		value, err = evaluateConjunction(env, conjunction)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return boolToInt(value != 0), nil
+}
+
+func evaluateConjunction(env *Environment, conjunction parser.Conjunction) (int, *CompilationError) {
+	value, err := evaluateComparison(env, conjunction.Left)
+	if err != nil || len(conjunction.Right) == 0 {
+		return value, err
+	}
+	for _, comparison := range conjunction.Right {
+		if value == 0 {
+			return 0, nil
+		}
+		value, err = evaluateComparison(env, comparison)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return boolToInt(value != 0), nil
+}
+
+func evaluateComparison(env *Environment, comparison parser.Comparison) (int, *CompilationError) {
+	left, err := evaluateSum(env, comparison.Left)
+	if err != nil || comparison.Right == nil {
+		return left, err
+	}
+	right, err := evaluateSum(env, *comparison.Right)
+	if err != nil {
+		return 0, err
+	}
+	switch comparison.Operator {
+	case "==":
+		return boolToInt(left == right), nil
+	case "!=":
+		return boolToInt(left != right), nil
+	case "<":
+		return boolToInt(left < right), nil
+	case "<=":
+		return boolToInt(left <= right), nil
+	case ">":
+		return boolToInt(left > right), nil
+	case ">=":
+		return boolToInt(left >= right), nil
+	default:
+		panic("unhandled case")
+	}
+}
+
+func evaluateSum(env *Environment, sum parser.Sum) (int, *CompilationError) {
+	value, err := evaluateProduct(env, sum.Left)
+	if err != nil {
+		return 0, err
+	}
+	for _, term := range sum.Right {
+		right, err := evaluateProduct(env, term.Value)
+		if err != nil {
+			return 0, err
+		}
+		switch term.Operator {
+		case "+":
+			value += right
+		case "-":
+			value -= right
+		default:
+			panic("unhandled case")
+		}
+	}
+	return value, nil
+}
+
+func evaluateProduct(env *Environment, product parser.Product) (int, *CompilationError) {
+	value, err := evaluateValue(env, product.Left)
+	if err != nil {
+		return 0, err
+	}
+	for _, factor := range product.Right {
+		right, err := evaluateValue(env, factor.Value)
+		if err != nil {
+			return 0, err
+		}
+		switch factor.Operator {
+		case "*":
+			value *= right
+		case "/", "%":
+			if right == 0 {
+				return 0, &CompilationError{Code: CodeDivisionByZero, Message: "Division by zero", Location: factor.Value.Pos, End: factor.Value.EndPos}
+			}
+			if factor.Operator == "/" {
+				value /= right
+			} else {
+				value %= right
+			}
+		default:
+			panic("unhandled case")
+		}
+	}
+	return value, nil
+}
+
+func evaluateValue(env *Environment, value parser.ConstantValue) (int, *CompilationError) {
+	switch {
+	case value.Not != nil:
+		v, err := evaluateValue(env, *value.Not)
+		return boolToInt(v == 0), err
+	case value.Negate != nil:
+		v, err := evaluateValue(env, *value.Negate)
+		return -v, err
+	case value.Defined != "":
+		o, ok := env.Lookup(value.Defined)
+		if !ok {
+			return 0, nil
+		}
+		_, ok = o.(*Constant)
+		return boolToInt(ok), nil
+	case value.Number != nil:
+		return *value.Number, nil
+	case value.Constant != "":
+		o, ok := env.Lookup(value.Constant)
+		if !ok {
+			return 0, &CompilationError{Code: CodeUnknownConstant, Message: fmt.Sprintf("Constant '%s' is not defined", value.Constant), Location: value.Pos, End: value.EndPos}
+		}
+		switch o := o.(type) {
+		case *Constant:
+			return o.Value, nil
+		case *Argument:
+			v, ok, err := constant(o.Env, o.Expression)
+			if err != nil {
+				return 0, err
+			}
+			if !ok {
+				return 0, &CompilationError{Code: CodeNotConstant, Message: fmt.Sprintf("The argument '%s' is a symbol, which isn't known until linking", value.Constant), Location: value.Pos, End: value.EndPos}
+			}
+			return v, nil
+		default:
+			return 0, &CompilationError{Code: CodeNotConstant, Message: fmt.Sprintf("'%s' is a symbol, which isn't known until linking", value.Constant), Location: value.Pos, End: value.EndPos}
+		}
+	case value.Group != nil:
+		return evaluate(env, *value.Group)
+	default:
+		panic("unhandled case")
+	}
+}
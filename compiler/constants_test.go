@@ -0,0 +1,61 @@
+package compiler
+
+import (
+	"Sano/cpu"
+	"Sano/linker"
+	"Sano/parser"
+	"bytes"
+	"testing"
+)
+
+func TestConstantExpressions(t *testing.T) {
+	f, err := parser.Parser.ParseString("test.san", `@main {
+		lda #(1 + 2 * 3);
+		lda #((1 + 2) * 3);
+		lda #(WIDTH / 2 - 1);
+		lda #(WIDTH % 7);
+		ldx #(-(1 - 2));
+		ldy #(WIDTH > 100 && !defined(HEIGHT));
+		sta (0x9F00 + 0x20);
+		rts !;
+	}`)
+	if err != nil {
+		t.Fatalf("failed to parse: %s", err)
+	}
+	c := Compiler{Instructions: cpu.WDC65C02Opcodes, Defines: map[string]int{"WIDTH": 320}}
+	obj, errs := c.Compile(f)
+	if len(errs) > 0 {
+		t.Fatalf("failed to compile: %s", errs[0].String())
+	}
+	layout, err := linker.Link([]*linker.Object{obj}, 0x1000)
+	if err != nil {
+		t.Fatalf("failed to link: %s", err)
+	}
+	expected := []byte{
+		0xA9, 0x07,
+		0xA9, 0x09,
+		0xA9, 0x9F,
+		0xA9, 0x05,
+		0xA2, 0x01,
+		0xA0, 0x01,
+		0x8D, 0x20, 0x9F,
+		0x60,
+	}
+	if !bytes.Equal(layout.Code, expected) {
+		t.Fatalf("got % X, expected % X", layout.Code, expected)
+	}
+}
+
+func TestConstantExpressionErrors(t *testing.T) {
+	cases := map[string]string{
+		`@main { lda #(1 / 0); }`:    "test.san:1:19: Division by zero",
+		`@main { lda #(main + 1); }`: "test.san:1:15: 'main' is a symbol, which isn't known until linking",
+		`@main { lda #(NOPE); }`:     "test.san:1:15: Constant 'NOPE' is not defined",
+	}
+	for source, expected := range cases {
+		err, ok := compileError(t, source)
+		if got := err.String(); ok && got != expected {
+			t.Errorf("%q: got %q, expected %q", source, got, expected)
+		}
+	}
+}
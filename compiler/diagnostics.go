@@ -0,0 +1,86 @@
+package compiler
+
+import (
+	"Sano/diagnostic"
+	"fmt"
+
+	"github.com/alecthomas/participle/v2/lexer"
+)
+
+// The codes of every kind of CompilationError, which stay the same so that they can be looked up
+const (
+	CodeUnknownCPU            = "E100"
+	CodeUnsupportedCPU        = "E101"
+	CodeDuplicateMacro        = "E102"
+	CodeDuplicateParameter    = "E103"
+	CodeDuplicateSymbol       = "E104"
+	CodeInvalidOpcode         = "E105"
+	CodeUnsupportedOpcode     = "E106"
+	CodeInvalidAddressingMode = "E107"
+	CodeUnknownRegisterWidth  = "E108"
+	CodeOutOfRange            = "E109"
+	CodeUnknownSymbol         = "E110"
+	CodeNotASymbol            = "E111"
+	CodeUnknownMacro          = "E112"
+	CodeWrongArgumentCount    = "E113"
+	CodeMacroTooDeep          = "E114"
+	CodeInvalidRepeatCount    = "E115"
+	CodeIncludeNotLoaded      = "E116"
+	CodeDivisionByZero        = "E117"
+	CodeUnknownConstant       = "E118"
+	CodeNotConstant           = "E119"
+	CodeUnknownRegister       = "E120"
+)
+
+// Diagnostic describes the error for showing with the source it's about
+func (e *CompilationError) Diagnostic() diagnostic.Diagnostic {
+	d := diagnostic.Diagnostic{
+		Severity: diagnostic.Error,
+		Code:     e.Code,
+		Message:  e.Message,
+		Primary:  label(e.Location, e.End, ""),
+	}
+	for _, r := range e.Related {
+		d.Secondary = append(d.Secondary, label(r.Location, r.End, r.Message))
+	}
+	return d
+}
+
+func label(start, end lexer.Position, message string) diagnostic.Label {
+	return diagnostic.Label{
+		Filename:  start.Filename,
+		Line:      start.Line,
+		Column:    start.Column,
+		EndLine:   end.Line,
+		EndColumn: end.Column,
+		Message:   message,
+	}
+}
+
+// Declaration is something that has a name and a place where it was declared, for
+// errors about the same name being declared again
+type Declaration interface {
+	Object
+	Declared() (start, end lexer.Position)
+}
+
+// the error for declaring a name that's already declared, which says where it was declared
+// first if it knows. Names that the compiler was given, like defined constants, weren't
+// declared anywhere.
+func duplicate(env *Environment, name string, start, end lexer.Position) CompilationError {
+	err := CompilationError{
+		Code:     CodeDuplicateSymbol,
+		Message:  fmt.Sprintf("Duplicate symbol '%s'", name),
+		Location: start,
+		End:      end,
+	}
+	if o, ok := env.Lookup(name); ok {
+		if d, ok := o.(Declaration); ok {
+			first, firstEnd := d.Declared()
+			if first.Line > 0 {
+				err.Related = []Related{{Message: "first declared", Location: first, End: firstEnd}}
+			}
+		}
+	}
+	return err
+}
@@ -0,0 +1,58 @@
+package compiler
+
+import (
+	"Sano/diagnostic"
+	"reflect"
+	"testing"
+)
+
+func TestDuplicatesSayWhereTheyWereFirstDeclared(t *testing.T) {
+	cases := map[string]diagnostic.Diagnostic{
+		"@main {\n\t&loop:\n\tnop !;\n\t&loop:\n}": {
+			Code:      CodeDuplicateSymbol,
+			Message:   "Duplicate symbol 'loop'",
+			Primary:   diagnostic.Label{Filename: "test.san", Line: 4, Column: 2, EndLine: 4, EndColumn: 8},
+			Secondary: []diagnostic.Label{{Filename: "test.san", Line: 2, Column: 2, EndLine: 2, EndColumn: 8, Message: "first declared"}},
+		},
+		"@main { rts !; }\n@main { rts !; }": {
+			Code:      CodeDuplicateSymbol,
+			Message:   "Duplicate symbol 'main'",
+			Primary:   diagnostic.Label{Filename: "test.san", Line: 2, Column: 1, EndLine: 2, EndColumn: 17},
+			Secondary: []diagnostic.Label{{Filename: "test.san", Line: 1, Column: 1, EndLine: 1, EndColumn: 17, Message: "first declared"}},
+		},
+		".macro m() { nop !; }\n.macro m() { nop !; }\n@main { rts !; }": {
+			Code:      CodeDuplicateMacro,
+			Message:   "Duplicate macro 'm'",
+			Primary:   diagnostic.Label{Filename: "test.san", Line: 2, Column: 1, EndLine: 2, EndColumn: 22},
+			Secondary: []diagnostic.Label{{Filename: "test.san", Line: 1, Column: 1, EndLine: 1, EndColumn: 22, Message: "first declared"}},
+		},
+		".macro m(a, b, a) { nop !; }\n@main { rts !; }": {
+			Code:      CodeDuplicateParameter,
+			Message:   "Duplicate parameter 'a' in the macro 'm'",
+			Primary:   diagnostic.Label{Filename: "test.san", Line: 1, Column: 16, EndLine: 1, EndColumn: 17},
+			Secondary: []diagnostic.Label{{Filename: "test.san", Line: 1, Column: 10, EndLine: 1, EndColumn: 11, Message: "first declared"}},
+		},
+	}
+	for source, expected := range cases {
+		err, ok := compileError(t, source)
+		if got := err.Diagnostic(); ok && !reflect.DeepEqual(got, expected) {
+			t.Errorf("%q: got %+v, expected %+v", source, got, expected)
+		}
+	}
+}
+
+func TestMacroCallsAreSecondaryLabels(t *testing.T) {
+	err, ok := compileError(t, ".macro m(x) { lda #x; }\n@main { %m(300); }")
+	if !ok {
+		t.FailNow()
+	}
+	expected := diagnostic.Diagnostic{
+		Code:      CodeOutOfRange,
+		Message:   "The value $12C does not fit in a byte, which can be from -128 to $FF",
+		Primary:   diagnostic.Label{Filename: "test.san", Line: 2, Column: 12, EndLine: 2, EndColumn: 15},
+		Secondary: []diagnostic.Label{{Filename: "test.san", Line: 2, Column: 9, EndLine: 2, EndColumn: 17, Message: "in the macro 'm' called"}},
+	}
+	if got := err.Diagnostic(); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("got %+v, expected %+v", got, expected)
+	}
+}
@@ -0,0 +1,144 @@
+package compiler
+
+import (
+	"Sano/cpu"
+	"Sano/linker"
+	"Sano/parser"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writes the files into a new directory, returning where it is
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, text := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestIncludes(t *testing.T) {
+	// the macros are found in the include path from both files
+	dir := writeFiles(t, map[string]string{
+		"src/main.san": `
+			.include "common/helpers.san";
+			.include "macros.san";
+			@main { %clear(); jsr =helper; rts !; }`,
+		"src/common/helpers.san": `
+			.include "macros.san";
+			@helper { %clear(); rts !; }`,
+		"lib/macros.san": `.macro clear() { lda #0; }`,
+	})
+	loader := &parser.Loader{IncludePaths: []string{filepath.Join(dir, "lib")}}
+	f, err := loader.Load(filepath.Join(dir, "src", "main.san"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loader.Sources) != 3 {
+		t.Errorf("expected 3 files to be read, but %d were", len(loader.Sources))
+	}
+
+	// both files include the macros, but they're only declared once
+	c := Compiler{Instructions: cpu.WDC65C02Opcodes}
+	obj, errs := c.Compile(f)
+	for _, err := range errs {
+		t.Error(err.String())
+	}
+	if len(errs) > 0 {
+		t.FailNow()
+	}
+	layout, err := linker.Link([]*linker.Object{obj}, 0x1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []byte{0xA9, 0x00, 0x20, 0x06, 0x10, 0x60, 0xA9, 0x00, 0x60}
+	if !bytes.Equal(layout.Code, expected) {
+		t.Fatalf("got % X, expected % X", layout.Code, expected)
+	}
+}
+
+func TestIncludedErrorsHaveTheirFilename(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"main.san": ".include \"bad.san\";\n@main { jsr =bad; }",
+		"bad.san":  "@bad {\n\tfoo !;\n}",
+	})
+	f, err := (&parser.Loader{}).Load(filepath.Join(dir, "main.san"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := Compiler{Instructions: cpu.WDC65C02Opcodes}
+	_, errs := c.Compile(f)
+	if len(errs) != 1 {
+		t.Fatalf("expected one error, but got %d", len(errs))
+	}
+	expected := filepath.Join(dir, "bad.san") + ":2:2: Invalid opcode 'foo'"
+	if got := errs[0].String(); got != expected {
+		t.Fatalf("got %q, expected %q", got, expected)
+	}
+}
+
+func TestIncludesInBlocksThatArentChosen(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"main.san": ".if defined(DEBUG) {\n\t.include \"debug.san\";\n}\n@main { rts !; }",
+	})
+	f, err := (&parser.Loader{}).Load(filepath.Join(dir, "main.san"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// debug.san isn't needed without DEBUG
+	c := Compiler{Instructions: cpu.WDC65C02Opcodes}
+	if _, errs := c.Compile(f); len(errs) > 0 {
+		t.Fatalf("expected no errors, but got %s", errs[0].String())
+	}
+
+	c.Defines = map[string]int{"DEBUG": 1}
+	_, errs := c.Compile(f)
+	if len(errs) != 1 {
+		t.Fatalf("expected one error, but got %d", len(errs))
+	}
+	expected := filepath.Join(dir, "main.san") + ":2:2: couldn't find debug.san next to " + filepath.Join(dir, "main.san") + " or in any of the include paths"
+	if got := errs[0].String(); got != expected || errs[0].Code != parser.CodeInclude {
+		t.Fatalf("got %s %q, expected %s %q", errs[0].Code, got, parser.CodeInclude, expected)
+	}
+}
+
+func TestIncludeErrors(t *testing.T) {
+	cases := []struct {
+		files    map[string]string
+		expected string
+	}{
+		{map[string]string{"main.san": `.include "a.san";`, "a.san": `.include "b.san";`, "b.san": `.include "a.san";`}, "the files include each other in a cycle"},
+		{map[string]string{"main.san": `.include "main.san";`}, "the files include each other in a cycle"},
+		{map[string]string{"main.san": `.include "nope.san";`}, "couldn't find nope.san"},
+		{map[string]string{"main.san": `.include "a.san";`, "a.san": `.cpu "65C02";`}, "only the file being assembled can have a .cpu directive"},
+	}
+	for _, tc := range cases {
+		dir := writeFiles(t, tc.files)
+		_, err := (&parser.Loader{}).Load(filepath.Join(dir, "main.san"))
+		if err == nil || !strings.Contains(err.Error(), tc.expected) {
+			t.Errorf("%v: expected an error containing %q, but got %v", tc.files, tc.expected, err)
+			continue
+		}
+		// they're reported like the syntax errors, so --diagnostics json covers them
+		var errs parser.Errors
+		if !errors.As(err, &errs) {
+			t.Errorf("%v: expected parser.Errors, but got %T", tc.files, err)
+			continue
+		}
+		if d := errs.Diagnostics(); len(d) != 1 || d[0].Code != parser.CodeInclude {
+			t.Errorf("%v: expected one %s diagnostic, but got %+v", tc.files, parser.CodeInclude, d)
+		}
+	}
+}
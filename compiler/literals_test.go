@@ -0,0 +1,94 @@
+package compiler
+
+import (
+	"Sano/cpu"
+	"Sano/parser"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestNumberNotations(t *testing.T) {
+	code := compileAndLink(t, cpu.WDC65C02Opcodes, 0x1000, `@main {
+		// the same number every way it can be written
+		lda #26;
+		lda #0x1A;
+		lda #$1a;
+		lda #0b11010;
+		lda #%11010;
+		/* characters are their ASCII codes */
+		lda #'A';
+		lda #'\n';
+		lda #'\x7F';
+		lda #';';
+		sta =$9F20;
+		.byte (7 % 2), (%100 * $10);
+		rts !;
+	}`)
+	expected := []byte{
+		0xA9, 0x1A, 0xA9, 0x1A, 0xA9, 0x1A, 0xA9, 0x1A, 0xA9, 0x1A,
+		0xA9, 0x41, 0xA9, 0x0A, 0xA9, 0x7F, 0xA9, 0x3B,
+		0x8D, 0x20, 0x9F,
+		0x01, 0x40,
+		0x60,
+	}
+	if !bytes.Equal(code, expected) {
+		t.Fatalf("got % X, expected % X", code, expected)
+	}
+}
+
+func TestPercentIsBinaryOrRemainder(t *testing.T) {
+	code := compileAndLink(t, cpu.WDC65C02Opcodes, 0x1000, `@main {
+		// after a value in a constant expression, % is the remainder
+		.if 7 %10 == 7 { lda #%10; }
+		.repeat a, 14 {
+			.if a == 13 { .byte (a %10), (a * %10), ((a) %10), (a % %10); }
+		}
+		// and anywhere else it's binary
+		.repeat i, %11 { nop !; }
+		.byte %101;
+		rts !;
+	}`)
+	expected := []byte{
+		0xA9, 0x02,
+		0x03, 0x1A, 0x03, 0x01,
+		0xEA, 0xEA, 0xEA,
+		0x05,
+		0x60,
+	}
+	if !bytes.Equal(code, expected) {
+		t.Fatalf("got % X, expected % X", code, expected)
+	}
+}
+
+func TestNumbersMustFit(t *testing.T) {
+	cases := map[string]string{
+		`@main { lda #0x1FF; }`:                 "test.san:1:14: The value $1FF does not fit in a byte, which can be from -128 to $FF",
+		`@main { lda #(-129); }`:                "test.san:1:14: The value -129 does not fit in a byte, which can be from -128 to $FF",
+		`@main { lda :0x1234; }`:                "test.san:1:14: The value $1234 does not fit in a byte, which can be from -128 to $FF",
+		`@main { .word $10000; }`:               "test.san:1:15: The value $10000 does not fit in a word, which can be from -32768 to $FFFF",
+		`@main { bne ~200; }`:                   "test.san:1:14: The value $C8 does not fit in a branch offset, which can be from -128 to $7F",
+		`@main { bne ~(-129); }`:                "test.san:1:14: The value -129 does not fit in a branch offset, which can be from -128 to $7F",
+		`@main { .repeat i, 257 { .byte i; } }`: "test.san:1:32: The value $100 does not fit in a byte, which can be from -128 to $FF",
+		// the argument is what's wrong, not the macro
+		".macro m(x) { lda #x; }\n@main { %m(300); }": "test.san:2:12: The value $12C does not fit in a byte, which can be from -128 to $FF\n\tin the macro 'm' called at test.san:2:9",
+	}
+	for source, expected := range cases {
+		err, ok := compileError(t, source)
+		if got := err.String(); ok && got != expected {
+			t.Errorf("%q: got %q, expected %q", source, got, expected)
+		}
+	}
+}
+
+func TestInvalidCharacters(t *testing.T) {
+	for source, expected := range map[string]string{
+		`@main { lda #'é'; }`:  "only ASCII characters can be numbers",
+		`@main { lda #'ab'; }`: "unexpected token",
+	} {
+		_, err := parser.Parser.ParseString("test.san", source)
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("%q: expected an error containing %q, but got %v", source, expected, err)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package compiler
+
+import (
+	"Sano/parser"
+	"fmt"
+)
+
+// Argument is what a macro parameter stands for in one expansion of the macro
+type Argument struct {
+	MyName     string
+	Expression parser.Expression
+	// where the symbols in the expression are looked up, which is where the macro was called from
+	Env *Symbol
+}
+
+func (a *Argument) Name() string {
+	return a.MyName
+}
+
+func (*Argument) Parent() (Object, bool) {
+	return nil, false
+}
+
+func (*Argument) Property(string) (Object, bool) {
+	return nil, false
+}
+
+// how deeply macros can be expanded inside each other, which stops ones that expand into themselves
+const maxMacroDepth = 64
+
+// what an expression stands for, following macro parameters back to the arguments they were given
+func substitute(env *Symbol, expr parser.Expression) (*Symbol, parser.Expression) {
+	for {
+		symbol, ok := expr.(parser.Symbol)
+		if !ok {
+			return env, expr
+		}
+		o, ok := env.Lookup(symbol.Name)
+		if !ok {
+			return env, expr
+		}
+		arg, ok := o.(*Argument)
+		if !ok {
+			return env, expr
+		}
+		env, expr = arg.Env, arg.Expression
+	}
+}
+
+// compiles the statements of a macro in place of a call to it. Every expansion gets its own
+// environment, so the subsymbols it declares are separate from other expansions', and it can
+// only see its parameters and what's at the top level of the file.
+func (fc *fragmentCompiler) expand(call parser.MacroInvocation, env *Symbol) {
+	macro, ok := fc.macros[call.Name]
+	if !ok {
+		fc.errors = append(fc.errors, CompilationError{Code: CodeUnknownMacro, Message: fmt.Sprintf("Unknown macro '%s'", call.Name), Location: call.Pos, End: call.EndPos})
+		return
+	}
+	if len(call.Arguments) != len(macro.Parameters) {
+		fc.errors = append(fc.errors, CompilationError{Code: CodeWrongArgumentCount, Message: fmt.Sprintf("The macro '%s' takes %d arguments, but was given %d", call.Name, len(macro.Parameters), len(call.Arguments)), Location: call.Pos, End: call.EndPos})
+		return
+	}
+	if fc.depth == maxMacroDepth {
+		fc.errors = append(fc.errors, CompilationError{Code: CodeMacroTooDeep, Message: fmt.Sprintf("The macro '%s' is expanded inside itself too many times", call.Name), Location: call.Pos, End: call.EndPos})
+		return
+	}
+
+	fc.expansions++
+	expansion := fc.root.NewSymbol(fmt.Sprintf("%s/%%%s.%d", GlobalName(env), macro.Name, fc.expansions))
+	start := len(fc.errors)
+	for i, p := range macro.Parameters {
+		// parameters hide anything else with the same name
+		expansion.Bindings[p.Name] = &Argument{MyName: p.Name, Expression: call.Arguments[i], Env: env}
+	}
+	fc.bindSubsymbols(expansion, macro.Statements)
+
+	fc.depth++
+	for _, s := range macro.Statements {
+		fc.statement(s, expansion)
+	}
+	fc.depth--
+
+	// so that errors in the macro say where it was used as well as where in it they are
+	for i := start; i < len(fc.errors); i++ {
+		fc.errors[i].Related = append(fc.errors[i].Related, Related{Message: fmt.Sprintf("in the macro '%s' called", macro.Name), Location: call.Pos, End: call.EndPos})
+	}
+}
+
+// binds the subsymbols declared in statements that are compiled in a scope of their own
+func (fc *fragmentCompiler) bindSubsymbols(scope *Symbol, statements []parser.Statement) {
+	for _, s := range statements {
+		if s, ok := s.(parser.SymbolDeclaration); ok {
+			subsymbol := scope.NewSubsymbol(s.Name)
+			subsymbol.Pos, subsymbol.EndPos = s.Pos, s.EndPos
+			if !scope.Bind(s.Name, subsymbol) {
+				fc.errors = append(fc.errors, duplicate(&scope.Environment, s.Name, s.Pos, s.EndPos))
+			}
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package compiler
+
+import (
+	"Sano/cpu"
+	"Sano/linker"
+	"Sano/parser"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestMacros(t *testing.T) {
+	// in zero page so that loop fits in a byte
+	code := compileAndLink(t, cpu.WDC65C02Opcodes, 0x80, `
+	.macro wait(count) {
+		ldx #count;
+		&loop:
+		dex !;
+		bne ~loop;
+	}
+	.macro skipIfZero(target) {
+		cmp #0;
+		beq ~target;
+	}
+	.macro twice(count) {
+		%wait(count);
+		%wait(count);
+	}
+	@main {
+		%wait(3);
+		%skipIfZero(done);
+		%twice(loop);
+		&loop:
+		&done:
+		rts !;
+	}`)
+	expected := []byte{
+		0xA2, 0x03, 0xCA, 0xD0, 0xFD,
+		// the argument is the caller's done, not a symbol in the macro
+		0xC9, 0x00, 0xF0, 0x0A,
+		// and loop is the caller's here too, even though the macro has its own
+		0xA2, 0x93, 0xCA, 0xD0, 0xFD,
+		0xA2, 0x93, 0xCA, 0xD0, 0xFD,
+		0x60,
+	}
+	if !bytes.Equal(code, expected) {
+		t.Fatalf("got % X, expected % X", code, expected)
+	}
+}
+
+func TestMacroExpansionsComeFromTheirBody(t *testing.T) {
+	f, err := parser.Parser.ParseString("test.san", `.macro veraAddress(low, middle, high) {
+	stz =0x9F25;
+	lda #low;
+	sta =0x9F20;
+	lda #middle;
+	sta =0x9F21;
+	lda #high;
+	sta =0x9F22;
+}
+@main {
+	%veraAddress(0x0B, 0xFA, 0x01);
+	rts !;
+}`)
+	if err != nil {
+		t.Fatalf("failed to parse: %s", err)
+	}
+	c := Compiler{Instructions: cpu.WDC65C02Opcodes}
+	obj, errs := c.Compile(f)
+	if len(errs) > 0 {
+		t.Fatalf("failed to compile: %s", errs[0].String())
+	}
+	layout, err := linker.Link([]*linker.Object{obj}, 0x1000)
+	if err != nil {
+		t.Fatalf("failed to link: %s", err)
+	}
+
+	// the operands that are arguments are on the same lines as their instructions
+	expected := []struct {
+		start uint16
+		end   uint32
+		line  int32
+	}{
+		{0x1000, 0x1003, 2},
+		{0x1003, 0x1005, 3},
+		{0x1005, 0x1008, 4},
+		{0x1008, 0x100A, 5},
+		{0x100A, 0x100D, 6},
+		{0x100D, 0x100F, 7},
+		{0x100F, 0x1012, 8},
+		{0x1012, 0x1013, 12},
+	}
+	if len(layout.Lines) != len(expected) {
+		t.Fatalf("got %d lines, expected %d: %v", len(layout.Lines), len(expected), layout.Lines)
+	}
+	for i, e := range expected {
+		got := layout.Lines[i]
+		if got.Start != e.start || got.End != e.end || got.Position.Line != e.line {
+			t.Errorf("got %04X-%04X on line %d, expected %04X-%04X on line %d", got.Start, got.End, got.Position.Line, e.start, e.end, e.line)
+		}
+	}
+}
+
+func TestMacroErrors(t *testing.T) {
+	cases := map[string]string{
+		`.macro bad() { foo !; } @main { %bad(); }`: "test.san:1:16: Invalid opcode 'foo'\n\tin the macro 'bad' called at test.san:1:33",
+		`.macro m(a) { nop !; } @main { %m(); }`:    "test.san:1:32: The macro 'm' takes 1 arguments, but was given 0",
+		`.macro m() { %m(); } @main { %m(); }`:      "test.san:1:14: The macro 'm' is expanded inside itself too many times",
+		`.macro m() { nop !; } @main { %n(); }`:     "test.san:1:31: Unknown macro 'n'",
+		`.macro m() { lda =nope; } @main { %m(); }`: "test.san:1:19: Symbol not found: 'nope'\n\tin the macro 'm' called at test.san:1:35",
+	}
+	for source, expected := range cases {
+		err, ok := compileError(t, source)
+		if got := err.String(); ok && !strings.HasPrefix(got, expected) {
+			t.Errorf("%q: got %q, expected it to start with %q", source, got, expected)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package compiler
+
+import (
+	"Sano/parser"
+	"fmt"
+)
+
+// how many times a repeat block can compile its statements
+const maxRepeatCount = 0x10000
+
+// compiles the statements of a repeat block once for each value of its index. Unlike macro
+// expansions, every iteration can see everything where the block is, but the subsymbols it
+// declares are its own.
+func (fc *fragmentCompiler) repeat(r parser.Repeat, env *Symbol) {
+	count, err := evaluate(&env.Environment, r.Count)
+	if err != nil {
+		fc.errors = append(fc.errors, *err)
+		return
+	}
+	if count < 0 || count > maxRepeatCount {
+		fc.errors = append(fc.errors, CompilationError{Code: CodeInvalidRepeatCount, Message: fmt.Sprintf("A block can be repeated between 0 and %d times, but this one is repeated %d times", maxRepeatCount, count), Location: r.Pos, End: r.EndPos})
+		return
+	}
+
+	for i := 0; i < count; i++ {
+		fc.expansions++
+		iteration := env.NewSymbol(fmt.Sprintf("%s/%%repeat.%d", GlobalName(env), fc.expansions))
+		if r.Index != "" {
+			iteration.Bindings[r.Index] = &Constant{MyName: r.Index, Value: i}
+		}
+		start := len(fc.errors)
+		statements, errs := chosenStatements(&iteration.Environment, r.Statements)
+		fc.errors = append(fc.errors, errs...)
+		fc.bindSubsymbols(iteration, statements)
+		for _, s := range statements {
+			fc.statement(s, iteration)
+		}
+		if len(fc.errors) > start {
+			// the same mistake would be made by every iteration
+			return
+		}
+	}
+}
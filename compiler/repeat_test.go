@@ -0,0 +1,58 @@
+package compiler
+
+import (
+	"Sano/cpu"
+	"bytes"
+	"testing"
+)
+
+func TestRepeatBlocks(t *testing.T) {
+	code := compileAndLink(t, cpu.WDC65C02Opcodes, 0x1000, `
+	@main {
+		lda =table;
+		.repeat i, 3 {
+			ldx #(i * 3 + 1);
+			&loop:
+			dex !;
+			bne ~loop;
+		}
+		.repeat i, 1 + 1 {
+			.if i == 0 { nop !; } .else { inx !; }
+		}
+		.repeat 0 { brk !; }
+		rts !;
+	}
+	@table {
+		.repeat i, 4 { .byte (i * i), i; }
+		.word main, 0x1234;
+	}`)
+	expected := []byte{
+		0xAD, 0x15, 0x10,
+		// every iteration has its own loop
+		0xA2, 0x01, 0xCA, 0xD0, 0xFD,
+		0xA2, 0x04, 0xCA, 0xD0, 0xFD,
+		0xA2, 0x07, 0xCA, 0xD0, 0xFD,
+		0xEA, 0xE8,
+		0x60,
+		0x00, 0x00, 0x01, 0x01, 0x04, 0x02, 0x09, 0x03,
+		0x00, 0x10, 0x34, 0x12,
+	}
+	if !bytes.Equal(code, expected) {
+		t.Fatalf("got % X, expected % X", code, expected)
+	}
+}
+
+func TestRepeatErrors(t *testing.T) {
+	cases := map[string]string{
+		`@main { .repeat -1 { nop !; } }`:           "test.san:1:9: A block can be repeated between 0 and 65536 times, but this one is repeated -1 times",
+		`@main { .repeat main { nop !; } }`:         "test.san:1:17: 'main' is a symbol, which isn't known until linking",
+		`@main { .repeat i, 2 { .byte (i / 0); } }`: "test.san:1:35: Division by zero",
+		`@main { .repeat 2 { &a: &a: } }`:           "test.san:1:25: Duplicate symbol 'a'\n\tfirst declared at test.san:1:21",
+	}
+	for source, expected := range cases {
+		err, ok := compileError(t, source)
+		if got := err.String(); ok && got != expected {
+			t.Errorf("%q: got %q, expected %q", source, got, expected)
+		}
+	}
+}
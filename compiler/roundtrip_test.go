@@ -0,0 +1,95 @@
+package compiler
+
+import (
+	"Sano/cpu"
+	"Sano/linker"
+	"Sano/parser"
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+// operands that are recognisable in the output, sized for each addressing mode
+func testOperands(mode cpu.Mode) ([]string, []byte) {
+	var operands []string
+	var operandBytes []byte
+	for i, size := range mode.OperandSizes() {
+		switch size {
+		case 1:
+			operands = append(operands, fmt.Sprintf("0x%02X", 0x12+i))
+			operandBytes = append(operandBytes, byte(0x12+i))
+		case 2:
+			operands = append(operands, "0x1234")
+			operandBytes = append(operandBytes, 0x34, 0x12)
+		case 3:
+			operands = append(operands, "0x123456")
+			operandBytes = append(operandBytes, 0x56, 0x34, 0x12)
+		default:
+			panic("unhandled case")
+		}
+	}
+	return operands, operandBytes
+}
+
+// assembles every instruction in set on its own, checks that the right bytes
+// come out, and that disassembling those bytes gives back the same instruction
+func testRoundTrip(t *testing.T, set cpu.OpcodeSet, instructions cpu.OpcodeSet) {
+	const origin = 0x1000
+
+	for _, op := range instructions {
+		op := op
+		t.Run(fmt.Sprintf("%s %s", op.Operation, op.Mode), func(t *testing.T) {
+			operands, operandBytes := testOperands(op.Mode)
+			source := fmt.Sprintf("%s %s;", op.Operation, op.Mode.Syntax(operands...))
+
+			f, err := parser.Parser.ParseString("test.san", fmt.Sprintf("@main { %s }", source))
+			if err != nil {
+				t.Fatalf("failed to parse %q: %s", source, err)
+			}
+			c := Compiler{Instructions: set}
+			obj, errs := c.Compile(f)
+			if len(errs) > 0 {
+				t.Fatalf("failed to compile %q: %s", source, errs[0].String())
+			}
+			layout, err := linker.Link([]*linker.Object{obj}, origin)
+			if err != nil {
+				t.Fatalf("failed to link %q: %s", source, err)
+			}
+
+			expected := append([]byte{op.Hex}, operandBytes...)
+			if !bytes.Equal(layout.Code, expected) {
+				t.Fatalf("%q assembled to % X, expected % X", source, layout.Code, expected)
+			}
+			if len(layout.Code) != 1+op.Mode.OperandSize() {
+				t.Fatalf("%q is %d bytes long, expected %d", source, len(layout.Code), 1+op.Mode.OperandSize())
+			}
+
+			inst, err := set.Disassemble(layout.Code, origin)
+			if err != nil {
+				t.Fatalf("failed to disassemble % X: %s", layout.Code, err)
+			}
+			if inst.Operation != op.Operation || inst.Mode != op.Mode {
+				t.Fatalf("% X disassembled to %s %s, expected %s %s", layout.Code, inst.Operation, inst.Mode, op.Operation, op.Mode)
+			}
+			if !bytes.Equal(inst.Operand, operandBytes) {
+				t.Fatalf("% X disassembled with operand % X, expected % X", layout.Code, inst.Operand, operandBytes)
+			}
+		})
+	}
+}
+
+func TestBase6502RoundTrip(t *testing.T) {
+	testRoundTrip(t, cpu.Base6502Opcodes, cpu.Base6502Opcodes)
+}
+
+func TestWDC65C02RoundTrip(t *testing.T) {
+	testRoundTrip(t, cpu.WDC65C02Opcodes, cpu.WDC65C02ExtensionOpcodes)
+}
+
+func TestWDC65C816RoundTrip(t *testing.T) {
+	testRoundTrip(t, cpu.WDC65C816Opcodes, cpu.WDC65C816Opcodes)
+}
+
+func TestNMOS6502UndocumentedRoundTrip(t *testing.T) {
+	testRoundTrip(t, cpu.NMOS6502ExtendedOpcodes, cpu.NMOS6502UndocumentedOpcodes)
+}
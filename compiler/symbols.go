@@ -1,5 +1,7 @@
 package compiler
 
+import "github.com/alecthomas/participle/v2/lexer"
+
 type Symbollike interface {
 	Object
 	isSymbollike()
@@ -7,10 +9,17 @@ type Symbollike interface {
 
 type Symbol struct {
 	Environment
+
+	// where the symbol was declared, which is nowhere for the scopes the compiler makes
+	Pos, EndPos lexer.Position
 }
 
 func (*Symbol) isSymbollike() {}
 
+func (s *Symbol) Declared() (lexer.Position, lexer.Position) {
+	return s.Pos, s.EndPos
+}
+
 func (s *Symbol) NewSubsymbol(name string) *Subsymbol {
 	return &Subsymbol{
 		MyName:       name,
@@ -22,10 +31,15 @@ type Subsymbol struct {
 	MyName string
 
 	ParentSymbol *Symbol
+	Pos, EndPos  lexer.Position
 }
 
 func (*Subsymbol) isSymbollike() {}
 
+func (s *Subsymbol) Declared() (lexer.Position, lexer.Position) {
+	return s.Pos, s.EndPos
+}
+
 func (s *Subsymbol) Name() string {
 	return s.MyName
 }
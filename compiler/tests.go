@@ -0,0 +1,86 @@
+package compiler
+
+import (
+	"Sano/linker"
+	"Sano/parser"
+	"fmt"
+	"strings"
+
+	"github.com/alecthomas/participle/v2/lexer"
+)
+
+// the registers and flags tests can set and check
+var testRegisters = map[string]bool{
+	"a": true, "x": true, "y": true,
+	"c": true, "z": true, "i": true, "d": true, "v": true, "n": true,
+}
+
+func compileTest(env *Environment, test parser.Test) (*linker.Test, []CompilationError) {
+	var errors []CompilationError
+	compiled := &linker.Test{
+		Name: test.Name,
+		Span: spanOf(test.Pos, test.EndPos),
+	}
+
+	// tests can refer to anything at the top level of the file, but bind nothing themselves
+	scope := env.NewSymbol(test.Name)
+
+	access := func(kind linker.TestStep_Kind, location parser.TestLocation, value parser.Expression, pos, endPos lexer.Position) {
+		step := &linker.TestStep{Kind: kind, Span: spanOf(pos, endPos)}
+		if location.VRAM != nil {
+			// VRAM addresses take 17 bits
+			address, err := compileOperand(scope, location.VRAM, linker.SymbolSize_LONG)
+			if err != nil {
+				errors = append(errors, *err)
+				return
+			}
+			step.Address = address
+			step.Vram = true
+		} else if location.Address != nil {
+			address, err := compileOperand(scope, location.Address, linker.SymbolSize_WORD)
+			if err != nil {
+				errors = append(errors, *err)
+				return
+			}
+			step.Address = address
+		} else {
+			register := strings.ToLower(location.Register)
+			if !testRegisters[register] {
+				errors = append(errors, CompilationError{Code: CodeUnknownRegister, Message: fmt.Sprintf("Unknown register or flag '%s'", location.Register), Location: pos})
+				return
+			}
+			step.Register = register
+		}
+		v, err := compileOperand(scope, value, linker.SymbolSize_BYTE)
+		if err != nil {
+			errors = append(errors, *err)
+			return
+		}
+		step.Value = v
+		compiled.Steps = append(compiled.Steps, step)
+	}
+
+	for _, s := range test.Steps {
+		switch s := s.(type) {
+		case parser.TestSet:
+			access(linker.TestStep_SET, s.Location, s.Value, s.Pos, s.EndPos)
+		case parser.TestExpect:
+			access(linker.TestStep_EXPECT, s.Location, s.Value, s.Pos, s.EndPos)
+		case parser.TestCall:
+			target, err := compileOperand(scope, s.Target, linker.SymbolSize_WORD)
+			if err != nil {
+				errors = append(errors, *err)
+				continue
+			}
+			compiled.Steps = append(compiled.Steps, &linker.TestStep{
+				Kind:    linker.TestStep_CALL,
+				Address: target,
+				Span:    spanOf(s.Pos, s.EndPos),
+			})
+		default:
+			panic("unhandled case")
+		}
+	}
+
+	return compiled, errors
+}
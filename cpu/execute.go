@@ -0,0 +1,458 @@
+package cpu
+
+import "fmt"
+
+// finds where the operand of the instruction at pc is, and whether that crossed into
+// another page. For branches, it's the target, and whether that's in another page
+// to the next instruction. Modes that only the 65C816 has can't be simulated.
+func (c *CPU) effectiveAddress(mode Mode, pc uint16, next uint16) (uint16, bool, error) {
+	operand := pc + 1
+	crosses := func(from, to uint16) bool {
+		return from&0xFF00 != to&0xFF00
+	}
+
+	switch mode {
+	case Implied, Accumulator:
+		return 0, false, nil
+	case Immediate:
+		return operand, false, nil
+	case ZeroPage, ZeroPageRelative:
+		return uint16(c.Bus.Read(operand)), false, nil
+	case ZeroPageIndexedX:
+		return uint16(c.Bus.Read(operand) + c.X), false, nil
+	case ZeroPageIndexedY:
+		return uint16(c.Bus.Read(operand) + c.Y), false, nil
+	case Absolute:
+		return c.read16(operand), false, nil
+	case AbsoluteIndexedX:
+		base := c.read16(operand)
+		return base + uint16(c.X), crosses(base, base+uint16(c.X)), nil
+	case AbsoluteIndexedY:
+		base := c.read16(operand)
+		return base + uint16(c.Y), crosses(base, base+uint16(c.Y)), nil
+	case Indirect:
+		pointer := c.read16(operand)
+		if !c.CMOS && pointer&0xFF == 0xFF {
+			// the NMOS 6502 doesn't carry into the high byte of the pointer
+			return uint16(c.Bus.Read(pointer)) | uint16(c.Bus.Read(pointer&0xFF00))<<8, false, nil
+		}
+		return c.read16(pointer), false, nil
+	case AbsoluteIndexedIndirect:
+		return c.read16(c.read16(operand) + uint16(c.X)), false, nil
+	case XIndexedIndirect:
+		return c.readZeroPage16(c.Bus.Read(operand) + c.X), false, nil
+	case IndirectYIndexed:
+		base := c.readZeroPage16(c.Bus.Read(operand))
+		return base + uint16(c.Y), crosses(base, base+uint16(c.Y)), nil
+	case ZeroPageIndirect:
+		return c.readZeroPage16(c.Bus.Read(operand)), false, nil
+	case Relative:
+		target := next + uint16(int8(c.Bus.Read(operand)))
+		return target, crosses(next, target), nil
+	default:
+		return 0, false, fmt.Errorf("%s addressing isn't supported", mode)
+	}
+}
+
+// runs a decoded instruction, returning how many cycles it took
+func (c *CPU) execute(op OpcodeData) (int, error) {
+	pc := c.PC
+	next := pc + uint16(op.Length())
+	address, crossed, err := c.effectiveAddress(op.Mode, pc, next)
+	if err != nil {
+		return 0, fmt.Errorf("%s at $%04X can't be simulated: %w", op.Operation, pc, err)
+	}
+	c.PC = next
+
+	cycles := op.Cycles
+	if crossed && op.Penalties&PageCross != 0 {
+		cycles++
+	}
+	if c.flag(FlagDecimal) && op.Penalties&Decimal != 0 {
+		cycles++
+	}
+
+	load := func() byte {
+		if op.Mode == Accumulator {
+			return c.A
+		}
+		return c.Bus.Read(address)
+	}
+	store := func(value byte) {
+		if op.Mode == Accumulator {
+			c.A = value
+		} else {
+			c.Bus.Write(address, value)
+		}
+	}
+	branch := func(taken bool) {
+		if !taken {
+			return
+		}
+		if op.Penalties&BranchTaken != 0 {
+			cycles++
+			if crossed {
+				cycles++
+			}
+		}
+		c.PC = address
+	}
+
+	switch op.Operation {
+	case LDA:
+		c.A = load()
+		c.setNZ(c.A)
+	case LDX:
+		c.X = load()
+		c.setNZ(c.X)
+	case LDY:
+		c.Y = load()
+		c.setNZ(c.Y)
+	case STA:
+		store(c.A)
+	case STX:
+		store(c.X)
+	case STY:
+		store(c.Y)
+	case STZ:
+		store(0)
+
+	case TAX:
+		c.X = c.A
+		c.setNZ(c.X)
+	case TAY:
+		c.Y = c.A
+		c.setNZ(c.Y)
+	case TXA:
+		c.A = c.X
+		c.setNZ(c.A)
+	case TYA:
+		c.A = c.Y
+		c.setNZ(c.A)
+	case TSX:
+		c.X = c.S
+		c.setNZ(c.X)
+	case TXS:
+		c.S = c.X
+
+	case PHA:
+		c.push(c.A)
+	case PHX:
+		c.push(c.X)
+	case PHY:
+		c.push(c.Y)
+	case PHP:
+		c.push(c.P | FlagUnused | FlagBreak)
+	case PLA:
+		c.A = c.pull()
+		c.setNZ(c.A)
+	case PLX:
+		c.X = c.pull()
+		c.setNZ(c.X)
+	case PLY:
+		c.Y = c.pull()
+		c.setNZ(c.Y)
+	case PLP:
+		c.P = c.pull()&^FlagBreak | FlagUnused
+
+	case ADC:
+		c.add(load())
+	case SBC:
+		c.subtract(load())
+	case AND:
+		c.A &= load()
+		c.setNZ(c.A)
+	case ORA:
+		c.A |= load()
+		c.setNZ(c.A)
+	case EOR:
+		c.A ^= load()
+		c.setNZ(c.A)
+	case CMP:
+		c.compare(c.A, load())
+	case CPX:
+		c.compare(c.X, load())
+	case CPY:
+		c.compare(c.Y, load())
+	case BIT:
+		value := load()
+		c.setFlag(FlagZero, c.A&value == 0)
+		// the immediate form only has a zero flag to set
+		if op.Mode != Immediate {
+			c.setFlag(FlagNegative, value&0x80 != 0)
+			c.setFlag(FlagOverflow, value&0x40 != 0)
+		}
+
+	case INC:
+		value := load() + 1
+		store(value)
+		c.setNZ(value)
+	case DEC:
+		value := load() - 1
+		store(value)
+		c.setNZ(value)
+	case INX:
+		c.X++
+		c.setNZ(c.X)
+	case INY:
+		c.Y++
+		c.setNZ(c.Y)
+	case DEX:
+		c.X--
+		c.setNZ(c.X)
+	case DEY:
+		c.Y--
+		c.setNZ(c.Y)
+	case ASL:
+		store(c.shiftLeft(load(), false))
+	case ROL:
+		store(c.shiftLeft(load(), c.flag(FlagCarry)))
+	case LSR:
+		store(c.shiftRight(load(), false))
+	case ROR:
+		store(c.shiftRight(load(), c.flag(FlagCarry)))
+	case TSB:
+		value := load()
+		c.setFlag(FlagZero, c.A&value == 0)
+		store(value | c.A)
+	case TRB:
+		value := load()
+		c.setFlag(FlagZero, c.A&value == 0)
+		store(value &^ c.A)
+
+	case CLC:
+		c.setFlag(FlagCarry, false)
+	case SEC:
+		c.setFlag(FlagCarry, true)
+	case CLD:
+		c.setFlag(FlagDecimal, false)
+	case SED:
+		c.setFlag(FlagDecimal, true)
+	case CLI:
+		c.setFlag(FlagInterrupt, false)
+	case SEI:
+		c.setFlag(FlagInterrupt, true)
+	case CLV:
+		c.setFlag(FlagOverflow, false)
+
+	case BCC:
+		branch(!c.flag(FlagCarry))
+	case BCS:
+		branch(c.flag(FlagCarry))
+	case BNE:
+		branch(!c.flag(FlagZero))
+	case BEQ:
+		branch(c.flag(FlagZero))
+	case BPL:
+		branch(!c.flag(FlagNegative))
+	case BMI:
+		branch(c.flag(FlagNegative))
+	case BVC:
+		branch(!c.flag(FlagOverflow))
+	case BVS:
+		branch(c.flag(FlagOverflow))
+	case BRA:
+		branch(true)
+
+	case JMP:
+		c.PC = address
+	case JSR:
+		// the return address pushed is the last byte of the JSR
+		c.push16(next - 1)
+		c.PC = address
+	case RTS:
+		c.PC = c.pull16() + 1
+	case RTI:
+		c.P = c.pull()&^FlagBreak | FlagUnused
+		c.PC = c.pull16()
+	case BRK:
+		// BRK skips the byte after it
+		c.interrupt(IRQVector, pc+2, true)
+
+	case NOP:
+	case WAI:
+		c.Waiting = true
+	case STP:
+		c.Stopped = true
+
+	case RMB0, RMB1, RMB2, RMB3, RMB4, RMB5, RMB6, RMB7:
+		store(load() &^ (1 << (op.Operation - RMB0)))
+	case SMB0, SMB1, SMB2, SMB3, SMB4, SMB5, SMB6, SMB7:
+		store(load() | 1<<(op.Operation-SMB0))
+	case BBR0, BBR1, BBR2, BBR3, BBR4, BBR5, BBR6, BBR7:
+		c.branchOnBit(&cycles, next, load()&(1<<(op.Operation-BBR0)) == 0)
+	case BBS0, BBS1, BBS2, BBS3, BBS4, BBS5, BBS6, BBS7:
+		c.branchOnBit(&cycles, next, load()&(1<<(op.Operation-BBS0)) != 0)
+
+	case SLO:
+		value := c.shiftLeft(load(), false)
+		store(value)
+		c.A |= value
+		c.setNZ(c.A)
+	case RLA:
+		value := c.shiftLeft(load(), c.flag(FlagCarry))
+		store(value)
+		c.A &= value
+		c.setNZ(c.A)
+	case SRE:
+		value := c.shiftRight(load(), false)
+		store(value)
+		c.A ^= value
+		c.setNZ(c.A)
+	case RRA:
+		value := c.shiftRight(load(), c.flag(FlagCarry))
+		store(value)
+		c.add(value)
+	case SAX:
+		store(c.A & c.X)
+	case LAX:
+		c.A = load()
+		c.X = c.A
+		c.setNZ(c.A)
+	case DCP:
+		value := load() - 1
+		store(value)
+		c.compare(c.A, value)
+	case ISC:
+		value := load() + 1
+		store(value)
+		c.subtract(value)
+	case ANC:
+		c.A &= load()
+		c.setNZ(c.A)
+		c.setFlag(FlagCarry, c.A&0x80 != 0)
+	case ALR:
+		c.A = c.shiftRight(c.A&load(), false)
+	case ARR:
+		c.A &= load()
+		c.A = c.A>>1 | boolToBit(c.flag(FlagCarry))<<7
+		c.setNZ(c.A)
+		c.setFlag(FlagCarry, c.A&0x40 != 0)
+		c.setFlag(FlagOverflow, (c.A>>6^c.A>>5)&1 != 0)
+	case SBX:
+		value := load()
+		c.setFlag(FlagCarry, c.A&c.X >= value)
+		c.X = c.A&c.X - value
+		c.setNZ(c.X)
+
+	default:
+		c.PC = pc
+		return 0, fmt.Errorf("%s at $%04X can't be simulated", op.Operation, pc)
+	}
+
+	return cycles, nil
+}
+
+func boolToBit(b bool) byte {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// BBR and BBS have the branch offset as their last byte, after the zero page address
+func (c *CPU) branchOnBit(cycles *int, next uint16, taken bool) {
+	if !taken {
+		return
+	}
+	target := next + uint16(int8(c.Bus.Read(next-1)))
+	*cycles++
+	if target&0xFF00 != next&0xFF00 {
+		*cycles++
+	}
+	c.PC = target
+}
+
+func (c *CPU) shiftLeft(value byte, in bool) byte {
+	c.setFlag(FlagCarry, value&0x80 != 0)
+	value = value<<1 | boolToBit(in)
+	c.setNZ(value)
+	return value
+}
+
+func (c *CPU) shiftRight(value byte, in bool) byte {
+	c.setFlag(FlagCarry, value&0x01 != 0)
+	value = value>>1 | boolToBit(in)<<7
+	c.setNZ(value)
+	return value
+}
+
+func (c *CPU) compare(register, value byte) {
+	c.setFlag(FlagCarry, register >= value)
+	c.setNZ(register - value)
+}
+
+func (c *CPU) add(value byte) {
+	carry := int(boolToBit(c.flag(FlagCarry)))
+	binary := int(c.A) + int(value) + carry
+
+	if !c.flag(FlagDecimal) {
+		result := byte(binary)
+		c.setFlag(FlagOverflow, ^(c.A^value)&(c.A^result)&0x80 != 0)
+		c.setFlag(FlagCarry, binary > 0xFF)
+		c.A = result
+		c.setNZ(c.A)
+		return
+	}
+
+	lo := int(c.A&0x0F) + int(value&0x0F) + carry
+	hi := int(c.A&0xF0) + int(value&0xF0)
+	if lo > 0x09 {
+		lo += 0x06
+	}
+	if lo > 0x0F {
+		hi += 0x10
+	}
+	// the NMOS 6502 sets these from the result before the high digit is adjusted
+	c.setFlag(FlagOverflow, ^(c.A^value)&(c.A^byte(hi))&0x80 != 0)
+	c.setFlag(FlagNegative, hi&0x80 != 0)
+	c.setFlag(FlagZero, byte(binary) == 0)
+	if hi > 0x90 {
+		hi += 0x60
+	}
+	c.setFlag(FlagCarry, hi > 0xFF)
+	c.A = byte(hi&0xF0) | byte(lo&0x0F)
+	if c.CMOS {
+		c.setNZ(c.A)
+	}
+}
+
+func (c *CPU) subtract(value byte) {
+	borrow := 1 - int(boolToBit(c.flag(FlagCarry)))
+	binary := int(c.A) - int(value) - borrow
+	result := byte(binary)
+
+	// the carry and overflow flags are set the same way in decimal mode
+	c.setFlag(FlagOverflow, (c.A^value)&(c.A^result)&0x80 != 0)
+	c.setFlag(FlagCarry, binary >= 0)
+	if !c.flag(FlagDecimal) {
+		c.A = result
+		c.setNZ(c.A)
+		return
+	}
+
+	lo := int(c.A&0x0F) - int(value&0x0F) - borrow
+	if c.CMOS {
+		if binary < 0 {
+			binary -= 0x60
+		}
+		if lo < 0 {
+			binary -= 0x06
+		}
+		c.A = byte(binary)
+		c.setNZ(c.A)
+		return
+	}
+
+	// the NMOS 6502 leaves the negative and zero flags as they would be in binary
+	c.setNZ(result)
+	hi := int(c.A>>4) - int(value>>4)
+	if lo < 0 {
+		lo -= 6
+		hi--
+	}
+	if hi < 0 {
+		hi -= 6
+	}
+	c.A = byte(hi<<4) | byte(lo&0x0F)
+}
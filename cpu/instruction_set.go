@@ -1,5 +1,7 @@
 package cpu
 
+import "fmt"
+
 type Opcode byte
 
 const (
@@ -67,6 +69,80 @@ const (
 	PLX
 	PHY
 	PLY
+	RMB0
+	RMB1
+	RMB2
+	RMB3
+	RMB4
+	RMB5
+	RMB6
+	RMB7
+	SMB0
+	SMB1
+	SMB2
+	SMB3
+	SMB4
+	SMB5
+	SMB6
+	SMB7
+	BBR0
+	BBR1
+	BBR2
+	BBR3
+	BBR4
+	BBR5
+	BBR6
+	BBR7
+	BBS0
+	BBS1
+	BBS2
+	BBS3
+	BBS4
+	BBS5
+	BBS6
+	BBS7
+	WAI
+	STP
+	BRL
+	COP
+	JML
+	JSL
+	MVN
+	MVP
+	PEA
+	PEI
+	PER
+	PHB
+	PHD
+	PHK
+	PLB
+	PLD
+	REP
+	SEP
+	RTL
+	TCD
+	TCS
+	TDC
+	TSC
+	TXY
+	TYX
+	WDM
+	XBA
+	XCE
+
+	// undocumented NMOS 6502 instructions
+	SLO
+	RLA
+	SRE
+	RRA
+	SAX
+	LAX
+	DCP
+	ISC
+	ANC
+	ALR
+	ARR
+	SBX
 )
 
 var OpcodeNames = map[string]Opcode{
@@ -134,6 +210,94 @@ var OpcodeNames = map[string]Opcode{
 	"plx": PLX,
 	"phy": PHY,
 	"ply": PLY,
+
+	"rmb0": RMB0,
+	"rmb1": RMB1,
+	"rmb2": RMB2,
+	"rmb3": RMB3,
+	"rmb4": RMB4,
+	"rmb5": RMB5,
+	"rmb6": RMB6,
+	"rmb7": RMB7,
+
+	"smb0": SMB0,
+	"smb1": SMB1,
+	"smb2": SMB2,
+	"smb3": SMB3,
+	"smb4": SMB4,
+	"smb5": SMB5,
+	"smb6": SMB6,
+	"smb7": SMB7,
+
+	"bbr0": BBR0,
+	"bbr1": BBR1,
+	"bbr2": BBR2,
+	"bbr3": BBR3,
+	"bbr4": BBR4,
+	"bbr5": BBR5,
+	"bbr6": BBR6,
+	"bbr7": BBR7,
+
+	"bbs0": BBS0,
+	"bbs1": BBS1,
+	"bbs2": BBS2,
+	"bbs3": BBS3,
+	"bbs4": BBS4,
+	"bbs5": BBS5,
+	"bbs6": BBS6,
+	"bbs7": BBS7,
+
+	"wai": WAI,
+	"stp": STP,
+
+	"brl": BRL,
+	"cop": COP,
+	"jml": JML,
+	"jsl": JSL,
+	"mvn": MVN,
+	"mvp": MVP,
+	"pea": PEA,
+	"pei": PEI,
+	"per": PER,
+	"phb": PHB,
+	"phd": PHD,
+	"phk": PHK,
+	"plb": PLB,
+	"pld": PLD,
+	"rep": REP,
+	"sep": SEP,
+	"rtl": RTL,
+	"tcd": TCD,
+	"tcs": TCS,
+	"tdc": TDC,
+	"tsc": TSC,
+	"txy": TXY,
+	"tyx": TYX,
+	"wdm": WDM,
+	"xba": XBA,
+	"xce": XCE,
+
+	"slo": SLO,
+	"rla": RLA,
+	"sre": SRE,
+	"rra": RRA,
+	"sax": SAX,
+	"lax": LAX,
+	"dcp": DCP,
+	"isc": ISC,
+	"anc": ANC,
+	"alr": ALR,
+	"arr": ARR,
+	"sbx": SBX,
+}
+
+func (o Opcode) String() string {
+	for name, op := range OpcodeNames {
+		if op == o {
+			return name
+		}
+	}
+	return fmt.Sprintf("Opcode(%d)", byte(o))
 }
 
 type Mode byte
@@ -155,6 +319,23 @@ const (
 	Indirect
 	XIndexedIndirect
 	IndirectYIndexed
+	ZeroPageIndirect
+	AbsoluteIndexedIndirect
+
+	// a zero page address, followed by a branch target
+	ZeroPageRelative
+
+	// the 65C816's addressing modes
+	AbsoluteLong
+	AbsoluteLongIndexedX
+	AbsoluteIndirectLong
+	ZeroPageIndirectLong
+	ZeroPageIndirectLongIndexedY
+	StackRelative
+	StackRelativeIndirectIndexedY
+	RelativeLong
+	// a destination bank, followed by a source bank
+	BlockMove
 )
 
 func (s Mode) String() string {
@@ -185,6 +366,125 @@ func (s Mode) String() string {
 		return "x-indexed indirect zero page"
 	case IndirectYIndexed:
 		return "indirect y-indexed zero page"
+	case ZeroPageIndirect:
+		return "indirect zero page"
+	case AbsoluteIndexedIndirect:
+		return "x-indexed indirect absolute"
+	case ZeroPageRelative:
+		return "zero page and relative"
+	case AbsoluteLong:
+		return "absolute long"
+	case AbsoluteLongIndexedX:
+		return "x-indexed absolute long"
+	case AbsoluteIndirectLong:
+		return "indirect absolute long"
+	case ZeroPageIndirectLong:
+		return "indirect long zero page"
+	case ZeroPageIndirectLongIndexedY:
+		return "indirect long y-indexed zero page"
+	case StackRelative:
+		return "stack relative"
+	case StackRelativeIndirectIndexedY:
+		return "stack relative indirect y-indexed"
+	case RelativeLong:
+		return "relative long"
+	case BlockMove:
+		return "block move"
+	default:
+		panic("invalid addressing mode")
+	}
+}
+
+// OperandSize is the number of bytes following an opcode using this addressing mode
+func (s Mode) OperandSize() int {
+	switch s {
+	case Implied, Accumulator:
+		return 0
+	case Immediate, Relative, ZeroPage, ZeroPageIndexedX, ZeroPageIndexedY, XIndexedIndirect, IndirectYIndexed, ZeroPageIndirect,
+		ZeroPageIndirectLong, ZeroPageIndirectLongIndexedY, StackRelative, StackRelativeIndirectIndexedY:
+		return 1
+	case Absolute, AbsoluteIndexedX, AbsoluteIndexedY, Indirect, AbsoluteIndexedIndirect, ZeroPageRelative,
+		AbsoluteIndirectLong, RelativeLong, BlockMove:
+		return 2
+	case AbsoluteLong, AbsoluteLongIndexedX:
+		return 3
+	default:
+		panic("invalid addressing mode")
+	}
+}
+
+// OperandSizes is the size of each of the operands following an opcode using this addressing mode
+func (s Mode) OperandSizes() []int {
+	switch {
+	case s == ZeroPageRelative, s == BlockMove:
+		return []int{1, 1}
+	case s.OperandSize() == 0:
+		return nil
+	default:
+		return []int{s.OperandSize()}
+	}
+}
+
+// Syntax writes operands in this addressing mode the way they're written in sano's assembly language,
+// taking them in the order they're encoded in
+func (s Mode) Syntax(operands ...string) string {
+	var operand string
+	if len(operands) > 0 {
+		operand = operands[0]
+	}
+
+	switch s {
+	case Immediate:
+		return "#" + operand
+	case Implied:
+		return "!"
+	case Relative:
+		return "~" + operand
+	case Accumulator:
+		return "a"
+	case ZeroPage:
+		return ":" + operand
+	case ZeroPageIndexedX:
+		return ":" + operand + ", x"
+	case ZeroPageIndexedY:
+		return ":" + operand + ", y"
+	case Absolute:
+		return "=" + operand
+	case AbsoluteIndexedX:
+		return "=" + operand + ", x"
+	case AbsoluteIndexedY:
+		return "=" + operand + ", y"
+	case Indirect:
+		return "(=" + operand + ")"
+	case XIndexedIndirect:
+		return "(:" + operand + ", x)"
+	case IndirectYIndexed:
+		return "(:" + operand + "), y"
+	case ZeroPageIndirect:
+		return "(:" + operand + ")"
+	case AbsoluteIndexedIndirect:
+		return "(=" + operand + ", x)"
+	case ZeroPageRelative:
+		return ":" + operand + ", ~" + operands[1]
+	case AbsoluteLong:
+		return "==" + operand
+	case AbsoluteLongIndexedX:
+		return "==" + operand + ", x"
+	case AbsoluteIndirectLong:
+		return "[=" + operand + "]"
+	case ZeroPageIndirectLong:
+		return "[:" + operand + "]"
+	case ZeroPageIndirectLongIndexedY:
+		return "[:" + operand + "], y"
+	case StackRelative:
+		return ":" + operand + ", s"
+	case StackRelativeIndirectIndexedY:
+		return "(:" + operand + ", s), y"
+	case RelativeLong:
+		return "~" + operand
+	case BlockMove:
+		// written source first, but encoded destination first
+		return "#" + operands[1] + ", #" + operand
 	default:
 		panic("invalid addressing mode")
 	}
@@ -195,10 +495,53 @@ type OpcodeData struct {
 	Mode      Mode
 
 	Hex byte
+
+	// the fewest cycles the instruction takes
+	Cycles int
+	// the reasons it can take longer
+	Penalties Penalty
+}
+
+// Length is the number of bytes the instruction takes up, not counting
+// the extra byte immediates have when the 65C816's registers are wide
+func (o OpcodeData) Length() int {
+	return 1 + o.Mode.OperandSize()
+}
+
+// Without returns the set without any of the given operations
+func (o OpcodeSet) Without(ops ...Opcode) OpcodeSet {
+	ret := OpcodeSet{}
+	for _, op := range o {
+		excluded := false
+		for _, without := range ops {
+			if op.Operation == without {
+				excluded = true
+			}
+		}
+		if !excluded {
+			ret = append(ret, op)
+		}
+	}
+	return ret
+}
+
+// Replacing returns the set with the instructions in other in place of
+// the ones with the same operation and addressing mode
+func (o OpcodeSet) Replacing(other OpcodeSet) OpcodeSet {
+	ret := make(OpcodeSet, len(o))
+	for i, op := range o {
+		if replacement, ok := other.FindOne(op.Operation, op.Mode); ok {
+			op = replacement
+		}
+		ret[i] = op
+	}
+	return ret
 }
 
 func (o OpcodeSet) And(other OpcodeSet) OpcodeSet {
-	return append(o, other...)
+	ret := make(OpcodeSet, 0, len(o)+len(other))
+	ret = append(ret, o...)
+	return append(ret, other...)
 }
 
 func (o OpcodeSet) Find(of Opcode) OpcodeSet {
@@ -220,236 +563,605 @@ func (o OpcodeSet) FindOne(of Opcode, mode Mode) (OpcodeData, bool) {
 	return OpcodeData{}, false
 }
 
+// Includes checks that every instruction in other is in the set, encoded the same way
+func (o OpcodeSet) Includes(other OpcodeSet) bool {
+	for _, op := range other {
+		found, ok := o.FindOne(op.Operation, op.Mode)
+		if !ok || found.Hex != op.Hex {
+			return false
+		}
+	}
+	return true
+}
+
+// Decode finds the instruction encoded by the given byte
+func (o OpcodeSet) Decode(hex byte) (OpcodeData, bool) {
+	for _, op := range o {
+		if op.Hex == hex {
+			return op, true
+		}
+	}
+	return OpcodeData{}, false
+}
+
 type OpcodeSet []OpcodeData
 
+// Instruction is an instruction decoded from machine code
+type Instruction struct {
+	OpcodeData
+
+	Address uint16
+	Operand []byte
+}
+
+// Size is the number of bytes the instruction takes up
+func (i Instruction) Size() int {
+	return 1 + len(i.Operand)
+}
+
+// Value is the operand as a number
+func (i Instruction) Value() uint32 {
+	var v uint32
+	for n, b := range i.Operand {
+		v |= uint32(b) << (8 * n)
+	}
+	return v
+}
+
+// Target is the address a relative branch goes to, which is
+// always the last byte of the operand, or last two for long branches
+func (i Instruction) Target() uint16 {
+	next := i.Address + uint16(i.Size())
+	if i.Mode == RelativeLong {
+		return next + uint16(i.Value())
+	}
+	return next + uint16(int8(i.Operand[len(i.Operand)-1]))
+}
+
+// Disassemble decodes the instruction at the start of code, which is at the given address,
+// assuming 8-bit registers
+func (o OpcodeSet) Disassemble(code []byte, address uint16) (Instruction, error) {
+	return o.DisassembleWith(code, address, RegisterWidths{})
+}
+
+// DisassembleWith decodes the instruction at the start of code, which is at the given address,
+// using the given register widths to decide how long immediate operands are
+func (o OpcodeSet) DisassembleWith(code []byte, address uint16, widths RegisterWidths) (Instruction, error) {
+	if len(code) == 0 {
+		return Instruction{}, fmt.Errorf("no code to disassemble at $%04X", address)
+	}
+	op, ok := o.Decode(code[0])
+	if !ok {
+		return Instruction{}, fmt.Errorf("$%02X at $%04X is not a valid opcode", code[0], address)
+	}
+	size := widths.OperandSize(op)
+	if len(code) < 1+size {
+		return Instruction{}, fmt.Errorf("%s at $%04X is missing its operand", op.Operation, address)
+	}
+	return Instruction{
+		OpcodeData: op,
+		Address:    address,
+		Operand:    code[1 : 1+size],
+	}, nil
+}
+
 // The 6502 opcodes
 var Base6502Opcodes = OpcodeSet{
-	{LDA, Immediate, 0xA9},
-	{LDA, ZeroPage, 0xA9},
-	{LDA, ZeroPageIndexedX, 0xB5},
-	{LDA, Absolute, 0xAD},
-	{LDA, AbsoluteIndexedX, 0xBD},
-	{LDA, AbsoluteIndexedY, 0xB9},
-	{LDA, XIndexedIndirect, 0xA1},
-	{LDA, IndirectYIndexed, 0xB1},
-
-	{LDX, Immediate, 0xA2},
-	{LDX, ZeroPage, 0xA6},
-	{LDX, ZeroPageIndexedY, 0xB6},
-	{LDX, Absolute, 0xAE},
-	{LDX, AbsoluteIndexedY, 0xBE},
-
-	{LDY, Immediate, 0xa0},
-	{LDY, ZeroPage, 0xa4},
-	{LDY, ZeroPageIndexedX, 0xb4},
-	{LDY, Absolute, 0xac},
-	{LDY, AbsoluteIndexedX, 0xbc},
-
-	{STA, ZeroPage, 0x85},
-	{STA, ZeroPageIndexedX, 0x95},
-	{STA, Absolute, 0x8d},
-	{STA, AbsoluteIndexedX, 0x9d},
-	{STA, AbsoluteIndexedY, 0x99},
-	{STA, XIndexedIndirect, 0x81},
-	{STA, IndirectYIndexed, 0x91},
-	{STA, Indirect, 0x92},
-
-	{STX, ZeroPage, 0x86},
-	{STX, ZeroPageIndexedY, 0x96},
-	{STX, Absolute, 0x8e},
-
-	{STY, ZeroPage, 0x84},
-	{STY, ZeroPageIndexedX, 0x94},
-	{STY, Absolute, 0x8c},
-
-	{ADC, Immediate, 0x69},
-	{ADC, ZeroPage, 0x65},
-	{ADC, ZeroPageIndexedX, 0x75},
-	{ADC, Absolute, 0x6d},
-	{ADC, AbsoluteIndexedX, 0x7d},
-	{ADC, AbsoluteIndexedY, 0x79},
-	{ADC, XIndexedIndirect, 0x61},
-	{ADC, IndirectYIndexed, 0x71},
-
-	{SBC, Immediate, 0xe9},
-	{SBC, ZeroPage, 0xe5},
-	{SBC, ZeroPageIndexedX, 0xf5},
-	{SBC, Absolute, 0xed},
-	{SBC, AbsoluteIndexedX, 0xfd},
-	{SBC, AbsoluteIndexedY, 0xf9},
-	{SBC, XIndexedIndirect, 0xe1},
-	{SBC, IndirectYIndexed, 0xf1},
-
-	{CMP, Immediate, 0xc9},
-	{CMP, ZeroPage, 0xc5},
-	{CMP, ZeroPageIndexedX, 0xd5},
-	{CMP, Absolute, 0xcd},
-	{CMP, AbsoluteIndexedX, 0xdd},
-	{CMP, AbsoluteIndexedY, 0xd9},
-	{CMP, XIndexedIndirect, 0xc1},
-	{CMP, IndirectYIndexed, 0xd1},
-
-	{CPX, Immediate, 0xe0},
-	{CPX, ZeroPage, 0xe4},
-	{CPX, Absolute, 0xec},
-
-	{CPY, Immediate, 0xc0},
-	{CPY, ZeroPage, 0xc4},
-	{CPY, Absolute, 0xcc},
-
-	{BIT, ZeroPage, 0x24},
-	{BIT, Absolute, 0x2c},
-
-	{CLC, Implied, 0x18},
-	{SEC, Implied, 0x38},
-	{CLI, Implied, 0x58},
-	{SEI, Implied, 0x78},
-	{CLD, Implied, 0xd8},
-	{SED, Implied, 0xf8},
-	{CLV, Implied, 0xb8},
-
-	{BCC, Relative, 0x90},
-	{BCS, Relative, 0xb0},
-	{BEQ, Relative, 0xf0},
-	{BNE, Relative, 0xd0},
-	{BMI, Relative, 0x30},
-	{BPL, Relative, 0x10},
-	{BVC, Relative, 0x50},
-	{BVS, Relative, 0x70},
-
-	{BRK, Implied, 0x00},
-
-	{AND, Immediate, 0x29},
-	{AND, ZeroPage, 0x25},
-	{AND, ZeroPageIndexedX, 0x35},
-	{AND, Absolute, 0x2d},
-	{AND, AbsoluteIndexedX, 0x3d},
-	{AND, AbsoluteIndexedY, 0x39},
-	{AND, XIndexedIndirect, 0x21},
-	{AND, IndirectYIndexed, 0x31},
-
-	{ORA, Immediate, 0x09},
-	{ORA, ZeroPage, 0x05},
-	{ORA, ZeroPageIndexedX, 0x15},
-	{ORA, Absolute, 0x0d},
-	{ORA, AbsoluteIndexedX, 0x1d},
-	{ORA, AbsoluteIndexedY, 0x19},
-	{ORA, XIndexedIndirect, 0x01},
-	{ORA, IndirectYIndexed, 0x11},
-
-	{EOR, Immediate, 0x49},
-	{EOR, ZeroPage, 0x45},
-	{EOR, ZeroPageIndexedX, 0x55},
-	{EOR, Absolute, 0x4d},
-	{EOR, AbsoluteIndexedX, 0x5d},
-	{EOR, AbsoluteIndexedY, 0x59},
-	{EOR, XIndexedIndirect, 0x41},
-	{EOR, IndirectYIndexed, 0x51},
-
-	{INC, ZeroPage, 0xe6},
-	{INC, ZeroPageIndexedX, 0xf6},
-	{INC, Absolute, 0xee},
-	{INC, AbsoluteIndexedX, 0xfe},
-
-	{DEC, ZeroPage, 0xc6},
-	{DEC, ZeroPageIndexedX, 0xd6},
-	{DEC, Absolute, 0xce},
-	{DEC, AbsoluteIndexedX, 0xde},
-
-	{INX, Implied, 0xe8},
-	{INY, Implied, 0xc8},
-
-	{DEX, Implied, 0xca},
-	{DEY, Implied, 0x88},
-
-	{JMP, Absolute, 0x4c},
-	{JMP, Indirect, 0x6c},
-
-	{JSR, Absolute, 0x20},
-	{RTS, Implied, 0x60},
-
-	{RTI, Implied, 0x40},
-
-	{NOP, Implied, 0xea},
-
-	{TAX, Implied, 0xaa},
-	{TXA, Implied, 0x8a},
-	{TAY, Implied, 0xa8},
-	{TYA, Implied, 0x98},
-	{TXS, Implied, 0x9a},
-	{TSX, Implied, 0xba},
-
-	{PHA, Implied, 0x48},
-	{PLA, Implied, 0x68},
-	{PHP, Implied, 0x08},
-	{PLP, Implied, 0x28},
-
-	{ASL, Accumulator, 0x0a},
-	{ASL, ZeroPage, 0x06},
-	{ASL, ZeroPageIndexedX, 0x16},
-	{ASL, Absolute, 0x0e},
-	{ASL, AbsoluteIndexedX, 0x1e},
-
-	{LSR, Accumulator, 0x4a},
-	{LSR, ZeroPage, 0x46},
-	{LSR, ZeroPageIndexedX, 0x56},
-	{LSR, Absolute, 0x4e},
-	{LSR, AbsoluteIndexedX, 0x5e},
-
-	{ROL, Accumulator, 0x2a},
-	{ROL, ZeroPage, 0x26},
-	{ROL, ZeroPageIndexedX, 0x36},
-	{ROL, Absolute, 0x2e},
-	{ROL, AbsoluteIndexedX, 0x3e},
-
-	{ROR, Accumulator, 0x6a},
-	{ROR, ZeroPage, 0x66},
-	{ROR, ZeroPageIndexedX, 0x76},
-	{ROR, Absolute, 0x6e},
-	{ROR, AbsoluteIndexedX, 0x7e},
+	{LDA, Immediate, 0xA9, 2, 0},
+	{LDA, ZeroPage, 0xA5, 3, 0},
+	{LDA, ZeroPageIndexedX, 0xB5, 4, 0},
+	{LDA, Absolute, 0xAD, 4, 0},
+	{LDA, AbsoluteIndexedX, 0xBD, 4, PageCross},
+	{LDA, AbsoluteIndexedY, 0xB9, 4, PageCross},
+	{LDA, XIndexedIndirect, 0xA1, 6, 0},
+	{LDA, IndirectYIndexed, 0xB1, 5, PageCross},
+
+	{LDX, Immediate, 0xA2, 2, 0},
+	{LDX, ZeroPage, 0xA6, 3, 0},
+	{LDX, ZeroPageIndexedY, 0xB6, 4, 0},
+	{LDX, Absolute, 0xAE, 4, 0},
+	{LDX, AbsoluteIndexedY, 0xBE, 4, PageCross},
+
+	{LDY, Immediate, 0xa0, 2, 0},
+	{LDY, ZeroPage, 0xa4, 3, 0},
+	{LDY, ZeroPageIndexedX, 0xb4, 4, 0},
+	{LDY, Absolute, 0xac, 4, 0},
+	{LDY, AbsoluteIndexedX, 0xbc, 4, PageCross},
+
+	{STA, ZeroPage, 0x85, 3, 0},
+	{STA, ZeroPageIndexedX, 0x95, 4, 0},
+	{STA, Absolute, 0x8d, 4, 0},
+	{STA, AbsoluteIndexedX, 0x9d, 5, 0},
+	{STA, AbsoluteIndexedY, 0x99, 5, 0},
+	{STA, XIndexedIndirect, 0x81, 6, 0},
+	{STA, IndirectYIndexed, 0x91, 6, 0},
+
+	{STX, ZeroPage, 0x86, 3, 0},
+	{STX, ZeroPageIndexedY, 0x96, 4, 0},
+	{STX, Absolute, 0x8e, 4, 0},
+
+	{STY, ZeroPage, 0x84, 3, 0},
+	{STY, ZeroPageIndexedX, 0x94, 4, 0},
+	{STY, Absolute, 0x8c, 4, 0},
+
+	{ADC, Immediate, 0x69, 2, 0},
+	{ADC, ZeroPage, 0x65, 3, 0},
+	{ADC, ZeroPageIndexedX, 0x75, 4, 0},
+	{ADC, Absolute, 0x6d, 4, 0},
+	{ADC, AbsoluteIndexedX, 0x7d, 4, PageCross},
+	{ADC, AbsoluteIndexedY, 0x79, 4, PageCross},
+	{ADC, XIndexedIndirect, 0x61, 6, 0},
+	{ADC, IndirectYIndexed, 0x71, 5, PageCross},
+
+	{SBC, Immediate, 0xe9, 2, 0},
+	{SBC, ZeroPage, 0xe5, 3, 0},
+	{SBC, ZeroPageIndexedX, 0xf5, 4, 0},
+	{SBC, Absolute, 0xed, 4, 0},
+	{SBC, AbsoluteIndexedX, 0xfd, 4, PageCross},
+	{SBC, AbsoluteIndexedY, 0xf9, 4, PageCross},
+	{SBC, XIndexedIndirect, 0xe1, 6, 0},
+	{SBC, IndirectYIndexed, 0xf1, 5, PageCross},
+
+	{CMP, Immediate, 0xc9, 2, 0},
+	{CMP, ZeroPage, 0xc5, 3, 0},
+	{CMP, ZeroPageIndexedX, 0xd5, 4, 0},
+	{CMP, Absolute, 0xcd, 4, 0},
+	{CMP, AbsoluteIndexedX, 0xdd, 4, PageCross},
+	{CMP, AbsoluteIndexedY, 0xd9, 4, PageCross},
+	{CMP, XIndexedIndirect, 0xc1, 6, 0},
+	{CMP, IndirectYIndexed, 0xd1, 5, PageCross},
+
+	{CPX, Immediate, 0xe0, 2, 0},
+	{CPX, ZeroPage, 0xe4, 3, 0},
+	{CPX, Absolute, 0xec, 4, 0},
+
+	{CPY, Immediate, 0xc0, 2, 0},
+	{CPY, ZeroPage, 0xc4, 3, 0},
+	{CPY, Absolute, 0xcc, 4, 0},
+
+	{BIT, ZeroPage, 0x24, 3, 0},
+	{BIT, Absolute, 0x2c, 4, 0},
+
+	{CLC, Implied, 0x18, 2, 0},
+	{SEC, Implied, 0x38, 2, 0},
+	{CLI, Implied, 0x58, 2, 0},
+	{SEI, Implied, 0x78, 2, 0},
+	{CLD, Implied, 0xd8, 2, 0},
+	{SED, Implied, 0xf8, 2, 0},
+	{CLV, Implied, 0xb8, 2, 0},
+
+	{BCC, Relative, 0x90, 2, BranchTaken},
+	{BCS, Relative, 0xb0, 2, BranchTaken},
+	{BEQ, Relative, 0xf0, 2, BranchTaken},
+	{BNE, Relative, 0xd0, 2, BranchTaken},
+	{BMI, Relative, 0x30, 2, BranchTaken},
+	{BPL, Relative, 0x10, 2, BranchTaken},
+	{BVC, Relative, 0x50, 2, BranchTaken},
+	{BVS, Relative, 0x70, 2, BranchTaken},
+
+	{BRK, Implied, 0x00, 7, 0},
+
+	{AND, Immediate, 0x29, 2, 0},
+	{AND, ZeroPage, 0x25, 3, 0},
+	{AND, ZeroPageIndexedX, 0x35, 4, 0},
+	{AND, Absolute, 0x2d, 4, 0},
+	{AND, AbsoluteIndexedX, 0x3d, 4, PageCross},
+	{AND, AbsoluteIndexedY, 0x39, 4, PageCross},
+	{AND, XIndexedIndirect, 0x21, 6, 0},
+	{AND, IndirectYIndexed, 0x31, 5, PageCross},
+
+	{ORA, Immediate, 0x09, 2, 0},
+	{ORA, ZeroPage, 0x05, 3, 0},
+	{ORA, ZeroPageIndexedX, 0x15, 4, 0},
+	{ORA, Absolute, 0x0d, 4, 0},
+	{ORA, AbsoluteIndexedX, 0x1d, 4, PageCross},
+	{ORA, AbsoluteIndexedY, 0x19, 4, PageCross},
+	{ORA, XIndexedIndirect, 0x01, 6, 0},
+	{ORA, IndirectYIndexed, 0x11, 5, PageCross},
+
+	{EOR, Immediate, 0x49, 2, 0},
+	{EOR, ZeroPage, 0x45, 3, 0},
+	{EOR, ZeroPageIndexedX, 0x55, 4, 0},
+	{EOR, Absolute, 0x4d, 4, 0},
+	{EOR, AbsoluteIndexedX, 0x5d, 4, PageCross},
+	{EOR, AbsoluteIndexedY, 0x59, 4, PageCross},
+	{EOR, XIndexedIndirect, 0x41, 6, 0},
+	{EOR, IndirectYIndexed, 0x51, 5, PageCross},
+
+	{INC, ZeroPage, 0xe6, 5, 0},
+	{INC, ZeroPageIndexedX, 0xf6, 6, 0},
+	{INC, Absolute, 0xee, 6, 0},
+	{INC, AbsoluteIndexedX, 0xfe, 7, 0},
+
+	{DEC, ZeroPage, 0xc6, 5, 0},
+	{DEC, ZeroPageIndexedX, 0xd6, 6, 0},
+	{DEC, Absolute, 0xce, 6, 0},
+	{DEC, AbsoluteIndexedX, 0xde, 7, 0},
+
+	{INX, Implied, 0xe8, 2, 0},
+	{INY, Implied, 0xc8, 2, 0},
+
+	{DEX, Implied, 0xca, 2, 0},
+	{DEY, Implied, 0x88, 2, 0},
+
+	{JMP, Absolute, 0x4c, 3, 0},
+	{JMP, Indirect, 0x6c, 5, 0},
+
+	{JSR, Absolute, 0x20, 6, 0},
+	{RTS, Implied, 0x60, 6, 0},
+
+	{RTI, Implied, 0x40, 6, 0},
+
+	{NOP, Implied, 0xea, 2, 0},
+
+	{TAX, Implied, 0xaa, 2, 0},
+	{TXA, Implied, 0x8a, 2, 0},
+	{TAY, Implied, 0xa8, 2, 0},
+	{TYA, Implied, 0x98, 2, 0},
+	{TXS, Implied, 0x9a, 2, 0},
+	{TSX, Implied, 0xba, 2, 0},
+
+	{PHA, Implied, 0x48, 3, 0},
+	{PLA, Implied, 0x68, 4, 0},
+	{PHP, Implied, 0x08, 3, 0},
+	{PLP, Implied, 0x28, 4, 0},
+
+	{ASL, Accumulator, 0x0a, 2, 0},
+	{ASL, ZeroPage, 0x06, 5, 0},
+	{ASL, ZeroPageIndexedX, 0x16, 6, 0},
+	{ASL, Absolute, 0x0e, 6, 0},
+	{ASL, AbsoluteIndexedX, 0x1e, 7, 0},
+
+	{LSR, Accumulator, 0x4a, 2, 0},
+	{LSR, ZeroPage, 0x46, 5, 0},
+	{LSR, ZeroPageIndexedX, 0x56, 6, 0},
+	{LSR, Absolute, 0x4e, 6, 0},
+	{LSR, AbsoluteIndexedX, 0x5e, 7, 0},
+
+	{ROL, Accumulator, 0x2a, 2, 0},
+	{ROL, ZeroPage, 0x26, 5, 0},
+	{ROL, ZeroPageIndexedX, 0x36, 6, 0},
+	{ROL, Absolute, 0x2e, 6, 0},
+	{ROL, AbsoluteIndexedX, 0x3e, 7, 0},
+
+	{ROR, Accumulator, 0x6a, 2, 0},
+	{ROR, ZeroPage, 0x66, 5, 0},
+	{ROR, ZeroPageIndexedX, 0x76, 6, 0},
+	{ROR, Absolute, 0x6e, 6, 0},
+	{ROR, AbsoluteIndexedX, 0x7e, 7, 0},
 }
 
 // The opcodes found on the WDC 65C02
 var WDC65C02ExtensionOpcodes = OpcodeSet{
-	{LDA, Indirect, 0xB2},
+	{LDA, ZeroPageIndirect, 0xB2, 5, 0},
+
+	{STA, ZeroPageIndirect, 0x92, 5, 0},
+
+	{STZ, ZeroPage, 0x64, 3, 0},
+	{STZ, ZeroPageIndexedX, 0x74, 4, 0},
+	{STZ, Absolute, 0x9c, 4, 0},
+	{STZ, AbsoluteIndexedX, 0x9e, 5, 0},
 
-	{STZ, ZeroPage, 0x64},
-	{STZ, ZeroPageIndexedX, 0x74},
-	{STZ, Absolute, 0x9c},
-	{STZ, AbsoluteIndexedX, 0x9e},
+	{ADC, ZeroPageIndirect, 0x72, 5, 0},
 
-	{ADC, Indirect, 0x72},
+	{SBC, ZeroPageIndirect, 0xf2, 5, 0},
 
-	{SBC, Indirect, 0xf2},
+	{CMP, ZeroPageIndirect, 0xd2, 5, 0},
 
-	{CMP, Indirect, 0xd2},
+	{BIT, Immediate, 0x89, 2, 0},
+	{BIT, ZeroPageIndexedX, 0x34, 4, 0},
+	{BIT, AbsoluteIndexedX, 0x3c, 4, PageCross},
 
-	{BIT, Immediate, 0x89},
-	{BIT, ZeroPageIndexedX, 0x34},
-	{BIT, AbsoluteIndexedX, 0x3c},
+	{BRA, Relative, 0x80, 3, PageCross},
 
-	{BRA, Relative, 0x80},
+	{AND, ZeroPageIndirect, 0x32, 5, 0},
 
-	{AND, Indirect, 0x32},
+	{ORA, ZeroPageIndirect, 0x12, 5, 0},
 
-	{ORA, Indirect, 0x12},
+	{EOR, ZeroPageIndirect, 0x52, 5, 0},
 
-	{EOR, Indirect, 0x52},
+	{INC, Accumulator, 0x1a, 2, 0},
 
-	{INC, Accumulator, 0x1a},
+	{DEC, Accumulator, 0x3a, 2, 0},
 
-	{DEC, Accumulator, 0x3a},
+	{JMP, AbsoluteIndexedIndirect, 0x7c, 6, 0},
 
-	{JMP, AbsoluteIndexedX, 0x7c},
+	{TRB, ZeroPage, 0x14, 5, 0},
+	{TRB, Absolute, 0x1c, 6, 0},
 
-	{TRB, ZeroPage, 0x14},
-	{TRB, Absolute, 0x1c},
+	{TSB, ZeroPage, 0x04, 5, 0},
+	{TSB, Absolute, 0x0c, 6, 0},
 
-	{TSB, ZeroPage, 0x04},
-	{TSB, Absolute, 0x0c},
+	{PHX, Implied, 0xda, 3, 0},
+	{PLX, Implied, 0xfa, 4, 0},
+	{PHY, Implied, 0x5a, 3, 0},
+	{PLY, Implied, 0x7a, 4, 0},
+
+	{WAI, Implied, 0xcb, 3, 0},
+	{STP, Implied, 0xdb, 3, 0},
+
+	{RMB0, ZeroPage, 0x07, 5, 0},
+	{RMB1, ZeroPage, 0x17, 5, 0},
+	{RMB2, ZeroPage, 0x27, 5, 0},
+	{RMB3, ZeroPage, 0x37, 5, 0},
+	{RMB4, ZeroPage, 0x47, 5, 0},
+	{RMB5, ZeroPage, 0x57, 5, 0},
+	{RMB6, ZeroPage, 0x67, 5, 0},
+	{RMB7, ZeroPage, 0x77, 5, 0},
+
+	{SMB0, ZeroPage, 0x87, 5, 0},
+	{SMB1, ZeroPage, 0x97, 5, 0},
+	{SMB2, ZeroPage, 0xa7, 5, 0},
+	{SMB3, ZeroPage, 0xb7, 5, 0},
+	{SMB4, ZeroPage, 0xc7, 5, 0},
+	{SMB5, ZeroPage, 0xd7, 5, 0},
+	{SMB6, ZeroPage, 0xe7, 5, 0},
+	{SMB7, ZeroPage, 0xf7, 5, 0},
+
+	{BBR0, ZeroPageRelative, 0x0f, 5, BranchTaken},
+	{BBR1, ZeroPageRelative, 0x1f, 5, BranchTaken},
+	{BBR2, ZeroPageRelative, 0x2f, 5, BranchTaken},
+	{BBR3, ZeroPageRelative, 0x3f, 5, BranchTaken},
+	{BBR4, ZeroPageRelative, 0x4f, 5, BranchTaken},
+	{BBR5, ZeroPageRelative, 0x5f, 5, BranchTaken},
+	{BBR6, ZeroPageRelative, 0x6f, 5, BranchTaken},
+	{BBR7, ZeroPageRelative, 0x7f, 5, BranchTaken},
+
+	{BBS0, ZeroPageRelative, 0x8f, 5, BranchTaken},
+	{BBS1, ZeroPageRelative, 0x9f, 5, BranchTaken},
+	{BBS2, ZeroPageRelative, 0xaf, 5, BranchTaken},
+	{BBS3, ZeroPageRelative, 0xbf, 5, BranchTaken},
+	{BBS4, ZeroPageRelative, 0xcf, 5, BranchTaken},
+	{BBS5, ZeroPageRelative, 0xdf, 5, BranchTaken},
+	{BBS6, ZeroPageRelative, 0xef, 5, BranchTaken},
+	{BBS7, ZeroPageRelative, 0xff, 5, BranchTaken},
+}
 
-	{PHX, Implied, 0xda},
-	{PLX, Implied, 0xfa},
-	{PHY, Implied, 0x5a},
-	{PLY, Implied, 0x7a},
+// The 65C02's bit instructions, which aren't on the 65C816
+var bitInstructions = []Opcode{
+	RMB0, RMB1, RMB2, RMB3, RMB4, RMB5, RMB6, RMB7,
+	SMB0, SMB1, SMB2, SMB3, SMB4, SMB5, SMB6, SMB7,
+	BBR0, BBR1, BBR2, BBR3, BBR4, BBR5, BBR6, BBR7,
+	BBS0, BBS1, BBS2, BBS3, BBS4, BBS5, BBS6, BBS7,
 }
+
+// The opcodes the WDC 65C816 adds to the WDC 65C02
+var WDC65C816ExtensionOpcodes = OpcodeSet{
+	{ORA, StackRelative, 0x03, 4, 0},
+	{ORA, ZeroPageIndirectLong, 0x07, 6, 0},
+	{ORA, AbsoluteLong, 0x0f, 5, 0},
+	{ORA, StackRelativeIndirectIndexedY, 0x13, 7, 0},
+	{ORA, ZeroPageIndirectLongIndexedY, 0x17, 6, 0},
+	{ORA, AbsoluteLongIndexedX, 0x1f, 5, 0},
+
+	{AND, StackRelative, 0x23, 4, 0},
+	{AND, ZeroPageIndirectLong, 0x27, 6, 0},
+	{AND, AbsoluteLong, 0x2f, 5, 0},
+	{AND, StackRelativeIndirectIndexedY, 0x33, 7, 0},
+	{AND, ZeroPageIndirectLongIndexedY, 0x37, 6, 0},
+	{AND, AbsoluteLongIndexedX, 0x3f, 5, 0},
+
+	{EOR, StackRelative, 0x43, 4, 0},
+	{EOR, ZeroPageIndirectLong, 0x47, 6, 0},
+	{EOR, AbsoluteLong, 0x4f, 5, 0},
+	{EOR, StackRelativeIndirectIndexedY, 0x53, 7, 0},
+	{EOR, ZeroPageIndirectLongIndexedY, 0x57, 6, 0},
+	{EOR, AbsoluteLongIndexedX, 0x5f, 5, 0},
+
+	{ADC, StackRelative, 0x63, 4, 0},
+	{ADC, ZeroPageIndirectLong, 0x67, 6, 0},
+	{ADC, AbsoluteLong, 0x6f, 5, 0},
+	{ADC, StackRelativeIndirectIndexedY, 0x73, 7, 0},
+	{ADC, ZeroPageIndirectLongIndexedY, 0x77, 6, 0},
+	{ADC, AbsoluteLongIndexedX, 0x7f, 5, 0},
+
+	{SBC, StackRelative, 0xe3, 4, 0},
+	{SBC, ZeroPageIndirectLong, 0xe7, 6, 0},
+	{SBC, AbsoluteLong, 0xef, 5, 0},
+	{SBC, StackRelativeIndirectIndexedY, 0xf3, 7, 0},
+	{SBC, ZeroPageIndirectLongIndexedY, 0xf7, 6, 0},
+	{SBC, AbsoluteLongIndexedX, 0xff, 5, 0},
+
+	{CMP, StackRelative, 0xc3, 4, 0},
+	{CMP, ZeroPageIndirectLong, 0xc7, 6, 0},
+	{CMP, AbsoluteLong, 0xcf, 5, 0},
+	{CMP, StackRelativeIndirectIndexedY, 0xd3, 7, 0},
+	{CMP, ZeroPageIndirectLongIndexedY, 0xd7, 6, 0},
+	{CMP, AbsoluteLongIndexedX, 0xdf, 5, 0},
+
+	{LDA, StackRelative, 0xa3, 4, 0},
+	{LDA, ZeroPageIndirectLong, 0xa7, 6, 0},
+	{LDA, AbsoluteLong, 0xaf, 5, 0},
+	{LDA, StackRelativeIndirectIndexedY, 0xb3, 7, 0},
+	{LDA, ZeroPageIndirectLongIndexedY, 0xb7, 6, 0},
+	{LDA, AbsoluteLongIndexedX, 0xbf, 5, 0},
+
+	{STA, StackRelative, 0x83, 4, 0},
+	{STA, ZeroPageIndirectLong, 0x87, 6, 0},
+	{STA, AbsoluteLong, 0x8f, 5, 0},
+	{STA, StackRelativeIndirectIndexedY, 0x93, 7, 0},
+	{STA, ZeroPageIndirectLongIndexedY, 0x97, 6, 0},
+	{STA, AbsoluteLongIndexedX, 0x9f, 5, 0},
+
+	{JSL, AbsoluteLong, 0x22, 8, 0},
+
+	{JML, AbsoluteLong, 0x5c, 4, 0},
+	{JML, AbsoluteIndirectLong, 0xdc, 6, 0},
+
+	{RTL, Implied, 0x6b, 6, 0},
+
+	{JSR, AbsoluteIndexedIndirect, 0xfc, 8, 0},
+
+	{BRL, RelativeLong, 0x82, 4, 0},
+
+	{PER, RelativeLong, 0x62, 6, 0},
+
+	{PEA, Absolute, 0xf4, 5, 0},
+
+	{PEI, ZeroPageIndirect, 0xd4, 6, 0},
+
+	{MVN, BlockMove, 0x54, 7, 0},
+
+	{MVP, BlockMove, 0x44, 7, 0},
+
+	{REP, Immediate, 0xc2, 3, 0},
+
+	{SEP, Immediate, 0xe2, 3, 0},
+
+	{COP, Immediate, 0x02, 7, 0},
+
+	{WDM, Immediate, 0x42, 2, 0},
+
+	{PHB, Implied, 0x8b, 3, 0},
+
+	{PLB, Implied, 0xab, 4, 0},
+
+	{PHD, Implied, 0x0b, 4, 0},
+
+	{PLD, Implied, 0x2b, 5, 0},
+
+	{PHK, Implied, 0x4b, 3, 0},
+
+	{TCD, Implied, 0x5b, 2, 0},
+
+	{TDC, Implied, 0x7b, 2, 0},
+
+	{TCS, Implied, 0x1b, 2, 0},
+
+	{TSC, Implied, 0x3b, 2, 0},
+
+	{TXY, Implied, 0x9b, 2, 0},
+
+	{TYX, Implied, 0xbb, 2, 0},
+
+	{XBA, Implied, 0xeb, 3, 0},
+
+	{XCE, Implied, 0xfb, 2, 0},
+}
+
+// The 6502 instructions that take a different number of cycles on the WDC 65C02
+var wdc65C02Timing = OpcodeSet{
+	{ADC, Immediate, 0x69, 2, Decimal},
+	{ADC, ZeroPage, 0x65, 3, Decimal},
+	{ADC, ZeroPageIndexedX, 0x75, 4, Decimal},
+	{ADC, Absolute, 0x6d, 4, Decimal},
+	{ADC, AbsoluteIndexedX, 0x7d, 4, PageCross | Decimal},
+	{ADC, AbsoluteIndexedY, 0x79, 4, PageCross | Decimal},
+	{ADC, XIndexedIndirect, 0x61, 6, Decimal},
+	{ADC, IndirectYIndexed, 0x71, 5, PageCross | Decimal},
+	{ADC, ZeroPageIndirect, 0x72, 5, Decimal},
+
+	{SBC, Immediate, 0xe9, 2, Decimal},
+	{SBC, ZeroPage, 0xe5, 3, Decimal},
+	{SBC, ZeroPageIndexedX, 0xf5, 4, Decimal},
+	{SBC, Absolute, 0xed, 4, Decimal},
+	{SBC, AbsoluteIndexedX, 0xfd, 4, PageCross | Decimal},
+	{SBC, AbsoluteIndexedY, 0xf9, 4, PageCross | Decimal},
+	{SBC, XIndexedIndirect, 0xe1, 6, Decimal},
+	{SBC, IndirectYIndexed, 0xf1, 5, PageCross | Decimal},
+	{SBC, ZeroPageIndirect, 0xf2, 5, Decimal},
+
+	{ASL, AbsoluteIndexedX, 0x1e, 6, PageCross},
+	{LSR, AbsoluteIndexedX, 0x5e, 6, PageCross},
+	{ROL, AbsoluteIndexedX, 0x3e, 6, PageCross},
+	{ROR, AbsoluteIndexedX, 0x7e, 6, PageCross},
+
+	{JMP, Indirect, 0x6c, 6, 0},
+}
+
+// The opcodes found on the WDC 65C02
+var WDC65C02Opcodes = Base6502Opcodes.And(WDC65C02ExtensionOpcodes).Replacing(wdc65C02Timing)
+
+// The opcodes found on the Rockwell 65C02, which has everything the WDC one does apart from WAI and STP
+var R65C02Opcodes = WDC65C02Opcodes.Without(WAI, STP)
+
+// The opcodes found on the WDC 65C816, which has all of the 65C02's apart from its bit instructions,
+// though it runs some of them in a different number of cycles
+var WDC65C816Opcodes = with65C816Penalties(Base6502Opcodes.And(WDC65C02ExtensionOpcodes).Without(bitInstructions...).And(WDC65C816ExtensionOpcodes))
+
+// The stable undocumented opcodes of the NMOS 6502. Other CPUs use these
+// encodings for their own instructions, or treat them as no-ops
+var NMOS6502UndocumentedOpcodes = OpcodeSet{
+	// ASL then ORA
+	{SLO, ZeroPage, 0x07, 5, 0},
+	{SLO, ZeroPageIndexedX, 0x17, 6, 0},
+	{SLO, Absolute, 0x0f, 6, 0},
+	{SLO, AbsoluteIndexedX, 0x1f, 7, 0},
+	{SLO, AbsoluteIndexedY, 0x1b, 7, 0},
+	{SLO, XIndexedIndirect, 0x03, 8, 0},
+	{SLO, IndirectYIndexed, 0x13, 8, 0},
+
+	// ROL then AND
+	{RLA, ZeroPage, 0x27, 5, 0},
+	{RLA, ZeroPageIndexedX, 0x37, 6, 0},
+	{RLA, Absolute, 0x2f, 6, 0},
+	{RLA, AbsoluteIndexedX, 0x3f, 7, 0},
+	{RLA, AbsoluteIndexedY, 0x3b, 7, 0},
+	{RLA, XIndexedIndirect, 0x23, 8, 0},
+	{RLA, IndirectYIndexed, 0x33, 8, 0},
+
+	// LSR then EOR
+	{SRE, ZeroPage, 0x47, 5, 0},
+	{SRE, ZeroPageIndexedX, 0x57, 6, 0},
+	{SRE, Absolute, 0x4f, 6, 0},
+	{SRE, AbsoluteIndexedX, 0x5f, 7, 0},
+	{SRE, AbsoluteIndexedY, 0x5b, 7, 0},
+	{SRE, XIndexedIndirect, 0x43, 8, 0},
+	{SRE, IndirectYIndexed, 0x53, 8, 0},
+
+	// ROR then ADC
+	{RRA, ZeroPage, 0x67, 5, 0},
+	{RRA, ZeroPageIndexedX, 0x77, 6, 0},
+	{RRA, Absolute, 0x6f, 6, 0},
+	{RRA, AbsoluteIndexedX, 0x7f, 7, 0},
+	{RRA, AbsoluteIndexedY, 0x7b, 7, 0},
+	{RRA, XIndexedIndirect, 0x63, 8, 0},
+	{RRA, IndirectYIndexed, 0x73, 8, 0},
+
+	// stores A AND X
+	{SAX, ZeroPage, 0x87, 3, 0},
+	{SAX, ZeroPageIndexedY, 0x97, 4, 0},
+	{SAX, Absolute, 0x8f, 4, 0},
+	{SAX, XIndexedIndirect, 0x83, 6, 0},
+
+	// LDA and LDX at once. The immediate form is unstable, so it's left out
+	{LAX, ZeroPage, 0xa7, 3, 0},
+	{LAX, ZeroPageIndexedY, 0xb7, 4, 0},
+	{LAX, Absolute, 0xaf, 4, 0},
+	{LAX, AbsoluteIndexedY, 0xbf, 4, PageCross},
+	{LAX, XIndexedIndirect, 0xa3, 6, 0},
+	{LAX, IndirectYIndexed, 0xb3, 5, PageCross},
+
+	// DEC then CMP
+	{DCP, ZeroPage, 0xc7, 5, 0},
+	{DCP, ZeroPageIndexedX, 0xd7, 6, 0},
+	{DCP, Absolute, 0xcf, 6, 0},
+	{DCP, AbsoluteIndexedX, 0xdf, 7, 0},
+	{DCP, AbsoluteIndexedY, 0xdb, 7, 0},
+	{DCP, XIndexedIndirect, 0xc3, 8, 0},
+	{DCP, IndirectYIndexed, 0xd3, 8, 0},
+
+	// INC then SBC
+	{ISC, ZeroPage, 0xe7, 5, 0},
+	{ISC, ZeroPageIndexedX, 0xf7, 6, 0},
+	{ISC, Absolute, 0xef, 6, 0},
+	{ISC, AbsoluteIndexedX, 0xff, 7, 0},
+	{ISC, AbsoluteIndexedY, 0xfb, 7, 0},
+	{ISC, XIndexedIndirect, 0xe3, 8, 0},
+	{ISC, IndirectYIndexed, 0xf3, 8, 0},
+
+	// AND, copying N into C. 0x2b does the same
+	{ANC, Immediate, 0x0b, 2, 0},
+
+	// AND then LSR
+	{ALR, Immediate, 0x4b, 2, 0},
+
+	// AND then ROR, with odd flags
+	{ARR, Immediate, 0x6b, 2, 0},
+
+	// X = (A AND X) - immediate, without borrow
+	{SBX, Immediate, 0xcb, 2, 0},
+}
+
+// The NMOS 6502 opcodes, including the undocumented ones
+var NMOS6502ExtendedOpcodes = Base6502Opcodes.And(NMOS6502UndocumentedOpcodes)
@@ -0,0 +1,104 @@
+package cpu
+
+import "testing"
+
+func checkErrors(t *testing.T, errs []error) {
+	t.Helper()
+	for _, err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestOpcodeSetsAreValid(t *testing.T) {
+	sets := map[string]OpcodeSet{
+		"6502":                 NMOS6502Reference,
+		"65C02":                WDC65C02Reference,
+		"Base6502Opcodes":      Base6502Opcodes,
+		"WDC65C02Extension":    WDC65C02ExtensionOpcodes,
+		"WDC65C02Opcodes":      WDC65C02Opcodes,
+		"R65C02Opcodes":        R65C02Opcodes,
+		"65C816":               WDC65C816Reference,
+		"WDC65C816Opcodes":     WDC65C816Opcodes,
+		"6502x":                NMOS6502ExtendedReference,
+		"NMOS6502Undocumented": NMOS6502UndocumentedOpcodes,
+		"NMOS6502Extended":     NMOS6502ExtendedOpcodes,
+	}
+	for name, set := range sets {
+		set := set
+		t.Run(name, func(t *testing.T) {
+			checkErrors(t, set.Validate())
+		})
+	}
+}
+
+func TestBase6502MatchesReference(t *testing.T) {
+	checkErrors(t, Base6502Opcodes.Compare(NMOS6502Reference))
+}
+
+func TestWDC65C02MatchesReference(t *testing.T) {
+	checkErrors(t, WDC65C02Opcodes.Compare(WDC65C02Reference))
+}
+
+func TestWDC65C816MatchesReference(t *testing.T) {
+	checkErrors(t, WDC65C816Opcodes.Compare(WDC65C816Reference))
+}
+
+func TestNMOS6502UndocumentedMatchesReference(t *testing.T) {
+	checkErrors(t, NMOS6502ExtendedOpcodes.Compare(NMOS6502ExtendedReference))
+}
+
+func TestTargetsIncludeTheBase6502(t *testing.T) {
+	for name, set := range Targets {
+		if !set.Includes(Base6502Opcodes) {
+			t.Errorf("%s cannot run code for a plain 6502", name)
+		}
+	}
+	if Base6502Opcodes.Includes(WDC65C02Opcodes) {
+		t.Errorf("a plain 6502 can run code for a 65C02")
+	}
+	if WDC65C02Opcodes.Includes(NMOS6502ExtendedOpcodes) {
+		t.Errorf("a 65C02 can run code that uses undocumented NMOS opcodes")
+	}
+	if WDC65C816Opcodes.Includes(R65C02Opcodes) {
+		t.Errorf("a 65C816 can run code for a Rockwell 65C02, even though it has no bit instructions")
+	}
+}
+
+func TestValidateFindsProblems(t *testing.T) {
+	set := OpcodeSet{
+		{LDA, Immediate, 0xA9, 2, 0},
+		{LDA, ZeroPage, 0xA9, 3, 0},
+		{LDA, Immediate, 0xAD, 2, 0},
+	}
+	if errs := set.Validate(); len(errs) != 2 {
+		t.Errorf("expected 2 problems, but got %d: %v", len(errs), errs)
+	}
+}
+
+func TestMaxCycles(t *testing.T) {
+	cases := []struct {
+		set       OpcodeSet
+		op        Opcode
+		mode      Mode
+		cycles    string
+		maxCycles int
+	}{
+		{Base6502Opcodes, LDA, AbsoluteIndexedX, "4-5", 5},
+		{Base6502Opcodes, BNE, Relative, "2-4", 4},
+		{Base6502Opcodes, ASL, AbsoluteIndexedX, "7", 7},
+		{WDC65C02Opcodes, ASL, AbsoluteIndexedX, "6-7", 7},
+		{WDC65C02Opcodes, ADC, AbsoluteIndexedX, "4-6", 6},
+		{WDC65C816Opcodes, ADC, AbsoluteIndexedX, "4-6", 6},
+		{WDC65C816Opcodes, INC, ZeroPage, "5-8", 8},
+		{WDC65C816Opcodes, ASL, Accumulator, "2", 2},
+	}
+	for _, c := range cases {
+		op, ok := c.set.FindOne(c.op, c.mode)
+		if !ok {
+			t.Fatalf("%s with %s addressing is missing", c.op, c.mode)
+		}
+		if op.CycleRange() != c.cycles || op.MaxCycles() != c.maxCycles {
+			t.Errorf("%s with %s addressing takes %s cycles, expected %s", c.op, c.mode, op.CycleRange(), c.cycles)
+		}
+	}
+}
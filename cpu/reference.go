@@ -0,0 +1,398 @@
+package cpu
+
+// The documented instructions of the NMOS 6502, in the order of their encodings,
+// for checking the hand-written opcode sets against
+var NMOS6502Reference = OpcodeSet{
+	{BRK, Implied, 0x00, 7, 0},
+	{ORA, XIndexedIndirect, 0x01, 6, 0},
+	{ORA, ZeroPage, 0x05, 3, 0},
+	{ASL, ZeroPage, 0x06, 5, 0},
+	{PHP, Implied, 0x08, 3, 0},
+	{ORA, Immediate, 0x09, 2, 0},
+	{ASL, Accumulator, 0x0A, 2, 0},
+	{ORA, Absolute, 0x0D, 4, 0},
+	{ASL, Absolute, 0x0E, 6, 0},
+	{BPL, Relative, 0x10, 2, BranchTaken},
+	{ORA, IndirectYIndexed, 0x11, 5, PageCross},
+	{ORA, ZeroPageIndexedX, 0x15, 4, 0},
+	{ASL, ZeroPageIndexedX, 0x16, 6, 0},
+	{CLC, Implied, 0x18, 2, 0},
+	{ORA, AbsoluteIndexedY, 0x19, 4, PageCross},
+	{ORA, AbsoluteIndexedX, 0x1D, 4, PageCross},
+	{ASL, AbsoluteIndexedX, 0x1E, 7, 0},
+	{JSR, Absolute, 0x20, 6, 0},
+	{AND, XIndexedIndirect, 0x21, 6, 0},
+	{BIT, ZeroPage, 0x24, 3, 0},
+	{AND, ZeroPage, 0x25, 3, 0},
+	{ROL, ZeroPage, 0x26, 5, 0},
+	{PLP, Implied, 0x28, 4, 0},
+	{AND, Immediate, 0x29, 2, 0},
+	{ROL, Accumulator, 0x2A, 2, 0},
+	{BIT, Absolute, 0x2C, 4, 0},
+	{AND, Absolute, 0x2D, 4, 0},
+	{ROL, Absolute, 0x2E, 6, 0},
+	{BMI, Relative, 0x30, 2, BranchTaken},
+	{AND, IndirectYIndexed, 0x31, 5, PageCross},
+	{AND, ZeroPageIndexedX, 0x35, 4, 0},
+	{ROL, ZeroPageIndexedX, 0x36, 6, 0},
+	{SEC, Implied, 0x38, 2, 0},
+	{AND, AbsoluteIndexedY, 0x39, 4, PageCross},
+	{AND, AbsoluteIndexedX, 0x3D, 4, PageCross},
+	{ROL, AbsoluteIndexedX, 0x3E, 7, 0},
+	{RTI, Implied, 0x40, 6, 0},
+	{EOR, XIndexedIndirect, 0x41, 6, 0},
+	{EOR, ZeroPage, 0x45, 3, 0},
+	{LSR, ZeroPage, 0x46, 5, 0},
+	{PHA, Implied, 0x48, 3, 0},
+	{EOR, Immediate, 0x49, 2, 0},
+	{LSR, Accumulator, 0x4A, 2, 0},
+	{JMP, Absolute, 0x4C, 3, 0},
+	{EOR, Absolute, 0x4D, 4, 0},
+	{LSR, Absolute, 0x4E, 6, 0},
+	{BVC, Relative, 0x50, 2, BranchTaken},
+	{EOR, IndirectYIndexed, 0x51, 5, PageCross},
+	{EOR, ZeroPageIndexedX, 0x55, 4, 0},
+	{LSR, ZeroPageIndexedX, 0x56, 6, 0},
+	{CLI, Implied, 0x58, 2, 0},
+	{EOR, AbsoluteIndexedY, 0x59, 4, PageCross},
+	{EOR, AbsoluteIndexedX, 0x5D, 4, PageCross},
+	{LSR, AbsoluteIndexedX, 0x5E, 7, 0},
+	{RTS, Implied, 0x60, 6, 0},
+	{ADC, XIndexedIndirect, 0x61, 6, 0},
+	{ADC, ZeroPage, 0x65, 3, 0},
+	{ROR, ZeroPage, 0x66, 5, 0},
+	{PLA, Implied, 0x68, 4, 0},
+	{ADC, Immediate, 0x69, 2, 0},
+	{ROR, Accumulator, 0x6A, 2, 0},
+	{JMP, Indirect, 0x6C, 5, 0},
+	{ADC, Absolute, 0x6D, 4, 0},
+	{ROR, Absolute, 0x6E, 6, 0},
+	{BVS, Relative, 0x70, 2, BranchTaken},
+	{ADC, IndirectYIndexed, 0x71, 5, PageCross},
+	{ADC, ZeroPageIndexedX, 0x75, 4, 0},
+	{ROR, ZeroPageIndexedX, 0x76, 6, 0},
+	{SEI, Implied, 0x78, 2, 0},
+	{ADC, AbsoluteIndexedY, 0x79, 4, PageCross},
+	{ADC, AbsoluteIndexedX, 0x7D, 4, PageCross},
+	{ROR, AbsoluteIndexedX, 0x7E, 7, 0},
+	{STA, XIndexedIndirect, 0x81, 6, 0},
+	{STY, ZeroPage, 0x84, 3, 0},
+	{STA, ZeroPage, 0x85, 3, 0},
+	{STX, ZeroPage, 0x86, 3, 0},
+	{DEY, Implied, 0x88, 2, 0},
+	{TXA, Implied, 0x8A, 2, 0},
+	{STY, Absolute, 0x8C, 4, 0},
+	{STA, Absolute, 0x8D, 4, 0},
+	{STX, Absolute, 0x8E, 4, 0},
+	{BCC, Relative, 0x90, 2, BranchTaken},
+	{STA, IndirectYIndexed, 0x91, 6, 0},
+	{STY, ZeroPageIndexedX, 0x94, 4, 0},
+	{STA, ZeroPageIndexedX, 0x95, 4, 0},
+	{STX, ZeroPageIndexedY, 0x96, 4, 0},
+	{TYA, Implied, 0x98, 2, 0},
+	{STA, AbsoluteIndexedY, 0x99, 5, 0},
+	{TXS, Implied, 0x9A, 2, 0},
+	{STA, AbsoluteIndexedX, 0x9D, 5, 0},
+	{LDY, Immediate, 0xA0, 2, 0},
+	{LDA, XIndexedIndirect, 0xA1, 6, 0},
+	{LDX, Immediate, 0xA2, 2, 0},
+	{LDY, ZeroPage, 0xA4, 3, 0},
+	{LDA, ZeroPage, 0xA5, 3, 0},
+	{LDX, ZeroPage, 0xA6, 3, 0},
+	{TAY, Implied, 0xA8, 2, 0},
+	{LDA, Immediate, 0xA9, 2, 0},
+	{TAX, Implied, 0xAA, 2, 0},
+	{LDY, Absolute, 0xAC, 4, 0},
+	{LDA, Absolute, 0xAD, 4, 0},
+	{LDX, Absolute, 0xAE, 4, 0},
+	{BCS, Relative, 0xB0, 2, BranchTaken},
+	{LDA, IndirectYIndexed, 0xB1, 5, PageCross},
+	{LDY, ZeroPageIndexedX, 0xB4, 4, 0},
+	{LDA, ZeroPageIndexedX, 0xB5, 4, 0},
+	{LDX, ZeroPageIndexedY, 0xB6, 4, 0},
+	{CLV, Implied, 0xB8, 2, 0},
+	{LDA, AbsoluteIndexedY, 0xB9, 4, PageCross},
+	{TSX, Implied, 0xBA, 2, 0},
+	{LDY, AbsoluteIndexedX, 0xBC, 4, PageCross},
+	{LDA, AbsoluteIndexedX, 0xBD, 4, PageCross},
+	{LDX, AbsoluteIndexedY, 0xBE, 4, PageCross},
+	{CPY, Immediate, 0xC0, 2, 0},
+	{CMP, XIndexedIndirect, 0xC1, 6, 0},
+	{CPY, ZeroPage, 0xC4, 3, 0},
+	{CMP, ZeroPage, 0xC5, 3, 0},
+	{DEC, ZeroPage, 0xC6, 5, 0},
+	{INY, Implied, 0xC8, 2, 0},
+	{CMP, Immediate, 0xC9, 2, 0},
+	{DEX, Implied, 0xCA, 2, 0},
+	{CPY, Absolute, 0xCC, 4, 0},
+	{CMP, Absolute, 0xCD, 4, 0},
+	{DEC, Absolute, 0xCE, 6, 0},
+	{BNE, Relative, 0xD0, 2, BranchTaken},
+	{CMP, IndirectYIndexed, 0xD1, 5, PageCross},
+	{CMP, ZeroPageIndexedX, 0xD5, 4, 0},
+	{DEC, ZeroPageIndexedX, 0xD6, 6, 0},
+	{CLD, Implied, 0xD8, 2, 0},
+	{CMP, AbsoluteIndexedY, 0xD9, 4, PageCross},
+	{CMP, AbsoluteIndexedX, 0xDD, 4, PageCross},
+	{DEC, AbsoluteIndexedX, 0xDE, 7, 0},
+	{CPX, Immediate, 0xE0, 2, 0},
+	{SBC, XIndexedIndirect, 0xE1, 6, 0},
+	{CPX, ZeroPage, 0xE4, 3, 0},
+	{SBC, ZeroPage, 0xE5, 3, 0},
+	{INC, ZeroPage, 0xE6, 5, 0},
+	{INX, Implied, 0xE8, 2, 0},
+	{SBC, Immediate, 0xE9, 2, 0},
+	{NOP, Implied, 0xEA, 2, 0},
+	{CPX, Absolute, 0xEC, 4, 0},
+	{SBC, Absolute, 0xED, 4, 0},
+	{INC, Absolute, 0xEE, 6, 0},
+	{BEQ, Relative, 0xF0, 2, BranchTaken},
+	{SBC, IndirectYIndexed, 0xF1, 5, PageCross},
+	{SBC, ZeroPageIndexedX, 0xF5, 4, 0},
+	{INC, ZeroPageIndexedX, 0xF6, 6, 0},
+	{SED, Implied, 0xF8, 2, 0},
+	{SBC, AbsoluteIndexedY, 0xF9, 4, PageCross},
+	{SBC, AbsoluteIndexedX, 0xFD, 4, PageCross},
+	{INC, AbsoluteIndexedX, 0xFE, 7, 0},
+}
+
+// The instructions the WDC 65C02 adds to the NMOS 6502, in the order of their encodings
+var WDC65C02ExtensionReference = OpcodeSet{
+	{TSB, ZeroPage, 0x04, 5, 0},
+	{RMB0, ZeroPage, 0x07, 5, 0},
+	{TSB, Absolute, 0x0C, 6, 0},
+	{BBR0, ZeroPageRelative, 0x0F, 5, BranchTaken},
+	{ORA, ZeroPageIndirect, 0x12, 5, 0},
+	{TRB, ZeroPage, 0x14, 5, 0},
+	{RMB1, ZeroPage, 0x17, 5, 0},
+	{INC, Accumulator, 0x1A, 2, 0},
+	{TRB, Absolute, 0x1C, 6, 0},
+	{BBR1, ZeroPageRelative, 0x1F, 5, BranchTaken},
+	{RMB2, ZeroPage, 0x27, 5, 0},
+	{BBR2, ZeroPageRelative, 0x2F, 5, BranchTaken},
+	{AND, ZeroPageIndirect, 0x32, 5, 0},
+	{BIT, ZeroPageIndexedX, 0x34, 4, 0},
+	{RMB3, ZeroPage, 0x37, 5, 0},
+	{DEC, Accumulator, 0x3A, 2, 0},
+	{BIT, AbsoluteIndexedX, 0x3C, 4, PageCross},
+	{BBR3, ZeroPageRelative, 0x3F, 5, BranchTaken},
+	{RMB4, ZeroPage, 0x47, 5, 0},
+	{BBR4, ZeroPageRelative, 0x4F, 5, BranchTaken},
+	{EOR, ZeroPageIndirect, 0x52, 5, 0},
+	{RMB5, ZeroPage, 0x57, 5, 0},
+	{PHY, Implied, 0x5A, 3, 0},
+	{BBR5, ZeroPageRelative, 0x5F, 5, BranchTaken},
+	{STZ, ZeroPage, 0x64, 3, 0},
+	{RMB6, ZeroPage, 0x67, 5, 0},
+	{BBR6, ZeroPageRelative, 0x6F, 5, BranchTaken},
+	{ADC, ZeroPageIndirect, 0x72, 5, 0},
+	{STZ, ZeroPageIndexedX, 0x74, 4, 0},
+	{RMB7, ZeroPage, 0x77, 5, 0},
+	{PLY, Implied, 0x7A, 4, 0},
+	{JMP, AbsoluteIndexedIndirect, 0x7C, 6, 0},
+	{BBR7, ZeroPageRelative, 0x7F, 5, BranchTaken},
+	{BRA, Relative, 0x80, 3, PageCross},
+	{SMB0, ZeroPage, 0x87, 5, 0},
+	{BIT, Immediate, 0x89, 2, 0},
+	{BBS0, ZeroPageRelative, 0x8F, 5, BranchTaken},
+	{STA, ZeroPageIndirect, 0x92, 5, 0},
+	{SMB1, ZeroPage, 0x97, 5, 0},
+	{STZ, Absolute, 0x9C, 4, 0},
+	{STZ, AbsoluteIndexedX, 0x9E, 5, 0},
+	{BBS1, ZeroPageRelative, 0x9F, 5, BranchTaken},
+	{SMB2, ZeroPage, 0xA7, 5, 0},
+	{BBS2, ZeroPageRelative, 0xAF, 5, BranchTaken},
+	{LDA, ZeroPageIndirect, 0xB2, 5, 0},
+	{SMB3, ZeroPage, 0xB7, 5, 0},
+	{BBS3, ZeroPageRelative, 0xBF, 5, BranchTaken},
+	{SMB4, ZeroPage, 0xC7, 5, 0},
+	{WAI, Implied, 0xCB, 3, 0},
+	{BBS4, ZeroPageRelative, 0xCF, 5, BranchTaken},
+	{CMP, ZeroPageIndirect, 0xD2, 5, 0},
+	{SMB5, ZeroPage, 0xD7, 5, 0},
+	{PHX, Implied, 0xDA, 3, 0},
+	{STP, Implied, 0xDB, 3, 0},
+	{BBS5, ZeroPageRelative, 0xDF, 5, BranchTaken},
+	{SMB6, ZeroPage, 0xE7, 5, 0},
+	{BBS6, ZeroPageRelative, 0xEF, 5, BranchTaken},
+	{SBC, ZeroPageIndirect, 0xF2, 5, 0},
+	{SMB7, ZeroPage, 0xF7, 5, 0},
+	{PLX, Implied, 0xFA, 4, 0},
+	{BBS7, ZeroPageRelative, 0xFF, 5, BranchTaken},
+}
+
+// The NMOS 6502 instructions the WDC 65C02 runs in a different number of cycles, in the order of their encodings
+var WDC65C02TimingReference = OpcodeSet{
+	{ASL, AbsoluteIndexedX, 0x1E, 6, PageCross},
+	{ROL, AbsoluteIndexedX, 0x3E, 6, PageCross},
+	{LSR, AbsoluteIndexedX, 0x5E, 6, PageCross},
+	{ADC, XIndexedIndirect, 0x61, 6, Decimal},
+	{ADC, ZeroPage, 0x65, 3, Decimal},
+	{ADC, Immediate, 0x69, 2, Decimal},
+	{JMP, Indirect, 0x6C, 6, 0},
+	{ADC, Absolute, 0x6D, 4, Decimal},
+	{ADC, IndirectYIndexed, 0x71, 5, Decimal | PageCross},
+	{ADC, ZeroPageIndirect, 0x72, 5, Decimal},
+	{ADC, ZeroPageIndexedX, 0x75, 4, Decimal},
+	{ADC, AbsoluteIndexedY, 0x79, 4, Decimal | PageCross},
+	{ADC, AbsoluteIndexedX, 0x7D, 4, Decimal | PageCross},
+	{ROR, AbsoluteIndexedX, 0x7E, 6, PageCross},
+	{SBC, XIndexedIndirect, 0xE1, 6, Decimal},
+	{SBC, ZeroPage, 0xE5, 3, Decimal},
+	{SBC, Immediate, 0xE9, 2, Decimal},
+	{SBC, Absolute, 0xED, 4, Decimal},
+	{SBC, IndirectYIndexed, 0xF1, 5, Decimal | PageCross},
+	{SBC, ZeroPageIndirect, 0xF2, 5, Decimal},
+	{SBC, ZeroPageIndexedX, 0xF5, 4, Decimal},
+	{SBC, AbsoluteIndexedY, 0xF9, 4, Decimal | PageCross},
+	{SBC, AbsoluteIndexedX, 0xFD, 4, Decimal | PageCross},
+}
+
+// The documented instructions of the WDC 65C02
+var WDC65C02Reference = NMOS6502Reference.And(WDC65C02ExtensionReference).Replacing(WDC65C02TimingReference)
+
+// The instructions the WDC 65C816 adds to the WDC 65C02, in the order of their encodings
+var WDC65C816ExtensionReference = OpcodeSet{
+	{COP, Immediate, 0x02, 7, 0},
+	{ORA, StackRelative, 0x03, 4, 0},
+	{ORA, ZeroPageIndirectLong, 0x07, 6, 0},
+	{PHD, Implied, 0x0B, 4, 0},
+	{ORA, AbsoluteLong, 0x0F, 5, 0},
+	{ORA, StackRelativeIndirectIndexedY, 0x13, 7, 0},
+	{ORA, ZeroPageIndirectLongIndexedY, 0x17, 6, 0},
+	{TCS, Implied, 0x1B, 2, 0},
+	{ORA, AbsoluteLongIndexedX, 0x1F, 5, 0},
+	{JSL, AbsoluteLong, 0x22, 8, 0},
+	{AND, StackRelative, 0x23, 4, 0},
+	{AND, ZeroPageIndirectLong, 0x27, 6, 0},
+	{PLD, Implied, 0x2B, 5, 0},
+	{AND, AbsoluteLong, 0x2F, 5, 0},
+	{AND, StackRelativeIndirectIndexedY, 0x33, 7, 0},
+	{AND, ZeroPageIndirectLongIndexedY, 0x37, 6, 0},
+	{TSC, Implied, 0x3B, 2, 0},
+	{AND, AbsoluteLongIndexedX, 0x3F, 5, 0},
+	{WDM, Immediate, 0x42, 2, 0},
+	{EOR, StackRelative, 0x43, 4, 0},
+	{MVP, BlockMove, 0x44, 7, 0},
+	{EOR, ZeroPageIndirectLong, 0x47, 6, 0},
+	{PHK, Implied, 0x4B, 3, 0},
+	{EOR, AbsoluteLong, 0x4F, 5, 0},
+	{EOR, StackRelativeIndirectIndexedY, 0x53, 7, 0},
+	{MVN, BlockMove, 0x54, 7, 0},
+	{EOR, ZeroPageIndirectLongIndexedY, 0x57, 6, 0},
+	{TCD, Implied, 0x5B, 2, 0},
+	{JML, AbsoluteLong, 0x5C, 4, 0},
+	{EOR, AbsoluteLongIndexedX, 0x5F, 5, 0},
+	{PER, RelativeLong, 0x62, 6, 0},
+	{ADC, StackRelative, 0x63, 4, 0},
+	{ADC, ZeroPageIndirectLong, 0x67, 6, 0},
+	{RTL, Implied, 0x6B, 6, 0},
+	{ADC, AbsoluteLong, 0x6F, 5, 0},
+	{ADC, StackRelativeIndirectIndexedY, 0x73, 7, 0},
+	{ADC, ZeroPageIndirectLongIndexedY, 0x77, 6, 0},
+	{TDC, Implied, 0x7B, 2, 0},
+	{ADC, AbsoluteLongIndexedX, 0x7F, 5, 0},
+	{BRL, RelativeLong, 0x82, 4, 0},
+	{STA, StackRelative, 0x83, 4, 0},
+	{STA, ZeroPageIndirectLong, 0x87, 6, 0},
+	{PHB, Implied, 0x8B, 3, 0},
+	{STA, AbsoluteLong, 0x8F, 5, 0},
+	{STA, StackRelativeIndirectIndexedY, 0x93, 7, 0},
+	{STA, ZeroPageIndirectLongIndexedY, 0x97, 6, 0},
+	{TXY, Implied, 0x9B, 2, 0},
+	{STA, AbsoluteLongIndexedX, 0x9F, 5, 0},
+	{LDA, StackRelative, 0xA3, 4, 0},
+	{LDA, ZeroPageIndirectLong, 0xA7, 6, 0},
+	{PLB, Implied, 0xAB, 4, 0},
+	{LDA, AbsoluteLong, 0xAF, 5, 0},
+	{LDA, StackRelativeIndirectIndexedY, 0xB3, 7, 0},
+	{LDA, ZeroPageIndirectLongIndexedY, 0xB7, 6, 0},
+	{TYX, Implied, 0xBB, 2, 0},
+	{LDA, AbsoluteLongIndexedX, 0xBF, 5, 0},
+	{REP, Immediate, 0xC2, 3, 0},
+	{CMP, StackRelative, 0xC3, 4, 0},
+	{CMP, ZeroPageIndirectLong, 0xC7, 6, 0},
+	{CMP, AbsoluteLong, 0xCF, 5, 0},
+	{CMP, StackRelativeIndirectIndexedY, 0xD3, 7, 0},
+	{PEI, ZeroPageIndirect, 0xD4, 6, 0},
+	{CMP, ZeroPageIndirectLongIndexedY, 0xD7, 6, 0},
+	{JML, AbsoluteIndirectLong, 0xDC, 6, 0},
+	{CMP, AbsoluteLongIndexedX, 0xDF, 5, 0},
+	{SEP, Immediate, 0xE2, 3, 0},
+	{SBC, StackRelative, 0xE3, 4, 0},
+	{SBC, ZeroPageIndirectLong, 0xE7, 6, 0},
+	{XBA, Implied, 0xEB, 3, 0},
+	{SBC, AbsoluteLong, 0xEF, 5, 0},
+	{SBC, StackRelativeIndirectIndexedY, 0xF3, 7, 0},
+	{PEA, Absolute, 0xF4, 5, 0},
+	{SBC, ZeroPageIndirectLongIndexedY, 0xF7, 6, 0},
+	{XCE, Implied, 0xFB, 2, 0},
+	{JSR, AbsoluteIndexedIndirect, 0xFC, 8, 0},
+	{SBC, AbsoluteLongIndexedX, 0xFF, 5, 0},
+}
+
+// The documented instructions of the WDC 65C816, which runs the 65C02's ones in the same number of cycles as the NMOS 6502
+var WDC65C816Reference = with65C816Penalties(NMOS6502Reference.And(WDC65C02ExtensionReference).Without(bitInstructions...).And(WDC65C816ExtensionReference))
+
+// The stable undocumented instructions of the NMOS 6502, in the order of their encodings
+var NMOS6502UndocumentedReference = OpcodeSet{
+	{SLO, XIndexedIndirect, 0x03, 8, 0},
+	{SLO, ZeroPage, 0x07, 5, 0},
+	{ANC, Immediate, 0x0B, 2, 0},
+	{SLO, Absolute, 0x0F, 6, 0},
+	{SLO, IndirectYIndexed, 0x13, 8, 0},
+	{SLO, ZeroPageIndexedX, 0x17, 6, 0},
+	{SLO, AbsoluteIndexedY, 0x1B, 7, 0},
+	{SLO, AbsoluteIndexedX, 0x1F, 7, 0},
+	{RLA, XIndexedIndirect, 0x23, 8, 0},
+	{RLA, ZeroPage, 0x27, 5, 0},
+	{RLA, Absolute, 0x2F, 6, 0},
+	{RLA, IndirectYIndexed, 0x33, 8, 0},
+	{RLA, ZeroPageIndexedX, 0x37, 6, 0},
+	{RLA, AbsoluteIndexedY, 0x3B, 7, 0},
+	{RLA, AbsoluteIndexedX, 0x3F, 7, 0},
+	{SRE, XIndexedIndirect, 0x43, 8, 0},
+	{SRE, ZeroPage, 0x47, 5, 0},
+	{ALR, Immediate, 0x4B, 2, 0},
+	{SRE, Absolute, 0x4F, 6, 0},
+	{SRE, IndirectYIndexed, 0x53, 8, 0},
+	{SRE, ZeroPageIndexedX, 0x57, 6, 0},
+	{SRE, AbsoluteIndexedY, 0x5B, 7, 0},
+	{SRE, AbsoluteIndexedX, 0x5F, 7, 0},
+	{RRA, XIndexedIndirect, 0x63, 8, 0},
+	{RRA, ZeroPage, 0x67, 5, 0},
+	{ARR, Immediate, 0x6B, 2, 0},
+	{RRA, Absolute, 0x6F, 6, 0},
+	{RRA, IndirectYIndexed, 0x73, 8, 0},
+	{RRA, ZeroPageIndexedX, 0x77, 6, 0},
+	{RRA, AbsoluteIndexedY, 0x7B, 7, 0},
+	{RRA, AbsoluteIndexedX, 0x7F, 7, 0},
+	{SAX, XIndexedIndirect, 0x83, 6, 0},
+	{SAX, ZeroPage, 0x87, 3, 0},
+	{SAX, Absolute, 0x8F, 4, 0},
+	{SAX, ZeroPageIndexedY, 0x97, 4, 0},
+	{LAX, XIndexedIndirect, 0xA3, 6, 0},
+	{LAX, ZeroPage, 0xA7, 3, 0},
+	{LAX, Absolute, 0xAF, 4, 0},
+	{LAX, IndirectYIndexed, 0xB3, 5, PageCross},
+	{LAX, ZeroPageIndexedY, 0xB7, 4, 0},
+	{LAX, AbsoluteIndexedY, 0xBF, 4, PageCross},
+	{DCP, XIndexedIndirect, 0xC3, 8, 0},
+	{DCP, ZeroPage, 0xC7, 5, 0},
+	{SBX, Immediate, 0xCB, 2, 0},
+	{DCP, Absolute, 0xCF, 6, 0},
+	{DCP, IndirectYIndexed, 0xD3, 8, 0},
+	{DCP, ZeroPageIndexedX, 0xD7, 6, 0},
+	{DCP, AbsoluteIndexedY, 0xDB, 7, 0},
+	{DCP, AbsoluteIndexedX, 0xDF, 7, 0},
+	{ISC, XIndexedIndirect, 0xE3, 8, 0},
+	{ISC, ZeroPage, 0xE7, 5, 0},
+	{ISC, Absolute, 0xEF, 6, 0},
+	{ISC, IndirectYIndexed, 0xF3, 8, 0},
+	{ISC, ZeroPageIndexedX, 0xF7, 6, 0},
+	{ISC, AbsoluteIndexedY, 0xFB, 7, 0},
+	{ISC, AbsoluteIndexedX, 0xFF, 7, 0},
+}
+
+// The NMOS 6502's instructions, documented or not
+var NMOS6502ExtendedReference = NMOS6502Reference.And(NMOS6502UndocumentedReference)
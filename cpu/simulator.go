@@ -0,0 +1,227 @@
+package cpu
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Bus is what a simulated CPU reads and writes through, which
+// can be plain memory or have devices mapped into it
+type Bus interface {
+	Read(address uint16) byte
+	Write(address uint16, value byte)
+}
+
+// Memory is a bus with 64K of RAM and nothing else
+type Memory [0x10000]byte
+
+func (m *Memory) Read(address uint16) byte {
+	return m[address]
+}
+
+func (m *Memory) Write(address uint16, value byte) {
+	m[address] = value
+}
+
+// The bits of the processor status register
+const (
+	FlagCarry     = 0x01
+	FlagZero      = 0x02
+	FlagInterrupt = 0x04
+	FlagDecimal   = 0x08
+	FlagBreak     = 0x10
+	// always set when the status register is pushed
+	FlagUnused   = 0x20
+	FlagOverflow = 0x40
+	FlagNegative = 0x80
+)
+
+// The addresses the CPU finds where to go from
+const (
+	NMIVector   = 0xFFFA
+	ResetVector = 0xFFFC
+	IRQVector   = 0xFFFE
+)
+
+// ErrStopped is returned when stepping a CPU that has run STP, until it's reset
+var ErrStopped = errors.New("the CPU is stopped")
+
+// CPU simulates a 6502 or 65C02 running the instructions in an opcode set, taking
+// the number of cycles the set says each one does. The 65C816's own instructions and
+// its native mode aren't simulated.
+type CPU struct {
+	A, X, Y byte
+	// the stack pointer, into page 1
+	S  byte
+	P  byte
+	PC uint16
+
+	// how many cycles have been run since the CPU was made
+	Cycles uint64
+
+	// CMOS CPUs fix the NMOS 6502's indirect JMP bug, set the flags properly
+	// in decimal mode, and clear decimal mode when interrupted
+	CMOS bool
+	// set by WAI until the next interrupt
+	Waiting bool
+	// set by STP until the next reset
+	Stopped bool
+
+	Bus Bus
+
+	decode     [256]*OpcodeData
+	irq        bool
+	nmiPending bool
+}
+
+// NewCPU makes a CPU running set connected to bus, which is treated as a
+// CMOS 65C02 if the set has BRA. It needs to be reset before it's run.
+func NewCPU(set OpcodeSet, bus Bus) *CPU {
+	c := &CPU{Bus: bus}
+	for i := range set {
+		c.decode[set[i].Hex] = &set[i]
+	}
+	_, c.CMOS = set.FindOne(BRA, Relative)
+	return c
+}
+
+// Reset starts the CPU again from the address in the reset vector
+func (c *CPU) Reset() {
+	c.S = 0xFD
+	c.P = FlagUnused | FlagInterrupt
+	if c.CMOS {
+		c.P &^= FlagDecimal
+	}
+	c.PC = c.read16(ResetVector)
+	c.Waiting = false
+	c.Stopped = false
+	c.nmiPending = false
+	c.Cycles += 7
+}
+
+// SetIRQ sets the level of the IRQ line, which interrupts the CPU
+// between instructions for as long as it's held and not masked
+func (c *CPU) SetIRQ(active bool) {
+	c.irq = active
+}
+
+// NMI interrupts the CPU before its next instruction, whether or not interrupts are masked
+func (c *CPU) NMI() {
+	c.nmiPending = true
+}
+
+func (c *CPU) read16(address uint16) uint16 {
+	return uint16(c.Bus.Read(address)) | uint16(c.Bus.Read(address+1))<<8
+}
+
+// reads a pointer from zero page, wrapping around within it
+func (c *CPU) readZeroPage16(address byte) uint16 {
+	return uint16(c.Bus.Read(uint16(address))) | uint16(c.Bus.Read(uint16(address+1)))<<8
+}
+
+func (c *CPU) push(value byte) {
+	c.Bus.Write(0x100|uint16(c.S), value)
+	c.S--
+}
+
+func (c *CPU) pull() byte {
+	c.S++
+	return c.Bus.Read(0x100 | uint16(c.S))
+}
+
+func (c *CPU) push16(value uint16) {
+	c.push(byte(value >> 8))
+	c.push(byte(value))
+}
+
+func (c *CPU) pull16() uint16 {
+	lo := c.pull()
+	return uint16(lo) | uint16(c.pull())<<8
+}
+
+func (c *CPU) setFlag(flag byte, on bool) {
+	if on {
+		c.P |= flag
+	} else {
+		c.P &^= flag
+	}
+}
+
+func (c *CPU) flag(flag byte) bool {
+	return c.P&flag != 0
+}
+
+func (c *CPU) setNZ(value byte) {
+	c.setFlag(FlagZero, value == 0)
+	c.setFlag(FlagNegative, value&0x80 != 0)
+}
+
+// pushes the program counter and status and jumps through a vector,
+// the same way for BRK as for interrupts apart from the break flag
+func (c *CPU) interrupt(vector uint16, pc uint16, brk bool) {
+	c.push16(pc)
+	if brk {
+		c.push(c.P | FlagUnused | FlagBreak)
+	} else {
+		c.push((c.P | FlagUnused) &^ FlagBreak)
+	}
+	c.P |= FlagInterrupt
+	if c.CMOS {
+		c.P &^= FlagDecimal
+	}
+	c.PC = c.read16(vector)
+}
+
+// Step runs the next instruction, or responds to an interrupt,
+// and returns the number of cycles that took
+func (c *CPU) Step() (int, error) {
+	if c.Stopped {
+		return 0, ErrStopped
+	}
+
+	if c.nmiPending {
+		c.nmiPending = false
+		c.Waiting = false
+		c.interrupt(NMIVector, c.PC, false)
+		c.Cycles += 7
+		return 7, nil
+	}
+	if c.irq && c.Waiting {
+		// WAI carries on without taking the interrupt when it's masked
+		c.Waiting = false
+	}
+	if c.irq && !c.flag(FlagInterrupt) {
+		c.interrupt(IRQVector, c.PC, false)
+		c.Cycles += 7
+		return 7, nil
+	}
+	if c.Waiting {
+		c.Cycles++
+		return 1, nil
+	}
+
+	op := c.decode[c.Bus.Read(c.PC)]
+	if op == nil {
+		return 0, fmt.Errorf("$%02X at $%04X is not an instruction", c.Bus.Read(c.PC), c.PC)
+	}
+	cycles, err := c.execute(*op)
+	if err != nil {
+		return 0, err
+	}
+	c.Cycles += uint64(cycles)
+	return cycles, nil
+}
+
+// Run steps the CPU until it has run for at least the given number of cycles,
+// returning how many it actually ran for
+func (c *CPU) Run(cycles int) (int, error) {
+	ran := 0
+	for ran < cycles {
+		n, err := c.Step()
+		ran += n
+		if err != nil {
+			return ran, err
+		}
+	}
+	return ran, nil
+}
@@ -0,0 +1,263 @@
+package cpu
+
+import (
+	"strings"
+	"testing"
+)
+
+// makes a CPU with the program at $0200 and the interrupt vectors pointing at $0300 and $0400
+func newTestCPU(t *testing.T, set OpcodeSet, program ...byte) (*CPU, *Memory) {
+	t.Helper()
+	memory := &Memory{}
+	copy(memory[0x0200:], program)
+	memory[ResetVector], memory[ResetVector+1] = 0x00, 0x02
+	memory[IRQVector], memory[IRQVector+1] = 0x00, 0x03
+	memory[NMIVector], memory[NMIVector+1] = 0x00, 0x04
+	c := NewCPU(set, memory)
+	c.Reset()
+	return c, memory
+}
+
+func step(t *testing.T, c *CPU, n int) int {
+	t.Helper()
+	total := 0
+	for i := 0; i < n; i++ {
+		cycles, err := c.Step()
+		if err != nil {
+			t.Fatalf("step %d: %s", i, err)
+		}
+		total += cycles
+	}
+	return total
+}
+
+func TestEveryInstructionCanBeSimulated(t *testing.T) {
+	sets := map[string]OpcodeSet{
+		"WDC65C02Opcodes":         WDC65C02Opcodes,
+		"NMOS6502ExtendedOpcodes": NMOS6502ExtendedOpcodes,
+	}
+	for name, set := range sets {
+		for _, op := range set {
+			c, _ := newTestCPU(t, set, op.Hex, 0x10, 0x10)
+			cycles, err := c.Step()
+			if err != nil {
+				t.Errorf("%s: %s with %s addressing: %s", name, op.Operation, op.Mode, err)
+			} else if cycles < op.Cycles || cycles > op.MaxCycles() {
+				t.Errorf("%s: %s with %s addressing took %d cycles, expected %s", name, op.Operation, op.Mode, cycles, op.CycleRange())
+			}
+		}
+	}
+}
+
+// the 65C816 has modes and instructions that the simulator doesn't have, which have to
+// be errors rather than running as something else
+func TestUnsupportedInstructionsAreErrors(t *testing.T) {
+	for _, op := range WDC65C816Opcodes {
+		c, _ := newTestCPU(t, WDC65C816Opcodes, op.Hex, 0x10, 0x10, 0x10)
+		cycles, err := c.Step()
+		if err != nil {
+			if !strings.Contains(err.Error(), "can't be simulated") {
+				t.Errorf("%s with %s addressing: unexpected error %s", op.Operation, op.Mode, err)
+			}
+			if c.PC != 0x0200 {
+				t.Errorf("%s with %s addressing moved the PC to $%04X when it failed", op.Operation, op.Mode, c.PC)
+			}
+			continue
+		}
+		if cycles < op.Cycles || cycles > op.MaxCycles() {
+			t.Errorf("%s with %s addressing took %d cycles, expected %s", op.Operation, op.Mode, cycles, op.CycleRange())
+		}
+	}
+
+	for _, code := range [][]byte{
+		{0xAF, 0x56, 0x34, 0x12}, // lda $123456
+		{0xA3, 0x01},             // lda 1,s
+	} {
+		c, _ := newTestCPU(t, WDC65C816Opcodes, code...)
+		if _, err := c.Step(); err == nil {
+			t.Errorf("% X: expected an error, but it loaded $%02X", code, c.A)
+		}
+	}
+}
+
+func TestLoopCycles(t *testing.T) {
+	c, _ := newTestCPU(t, Base6502Opcodes,
+		0xA2, 0x05, // ldx #5
+		0xCA,       // dex
+		0xD0, 0xFD, // bne -3
+		0xEA, // nop
+	)
+	cycles := step(t, c, 1+5*2)
+	if cycles != 2+5*2+4*3+2 {
+		t.Errorf("loop took %d cycles", cycles)
+	}
+	if c.PC != 0x0205 || c.X != 0 || !c.flag(FlagZero) {
+		t.Errorf("loop ended at $%04X with X = %d", c.PC, c.X)
+	}
+}
+
+func TestPageCrossingCycles(t *testing.T) {
+	c, memory := newTestCPU(t, Base6502Opcodes,
+		0xA2, 0x01, // ldx #1
+		0xBD, 0xFF, 0x10, // lda $10FF, x
+		0x9D, 0xFF, 0x10, // sta $10FF, x
+	)
+	memory[0x1100] = 0x42
+	if cycles := step(t, c, 2); cycles != 2+5 {
+		t.Errorf("load took %d cycles", cycles-2)
+	}
+	if c.A != 0x42 {
+		t.Errorf("loaded $%02X", c.A)
+	}
+	// stores always take the extra cycle
+	if cycles := step(t, c, 1); cycles != 5 {
+		t.Errorf("store took %d cycles", cycles)
+	}
+}
+
+func TestDecimalMode(t *testing.T) {
+	cases := []struct {
+		set            OpcodeSet
+		op             byte
+		a, value       byte
+		carry          bool
+		result         byte
+		carryOut, zero bool
+	}{
+		{WDC65C02Opcodes, 0x69, 0x09, 0x01, false, 0x10, false, false},
+		{WDC65C02Opcodes, 0x69, 0x58, 0x46, true, 0x05, true, false},
+		{WDC65C02Opcodes, 0x69, 0x99, 0x01, false, 0x00, true, true},
+		{Base6502Opcodes, 0x69, 0x99, 0x01, false, 0x00, true, false},
+		{WDC65C02Opcodes, 0xE9, 0x10, 0x01, true, 0x09, true, false},
+		{WDC65C02Opcodes, 0xE9, 0x00, 0x01, true, 0x99, false, false},
+		{Base6502Opcodes, 0xE9, 0x00, 0x01, true, 0x99, false, false},
+		{Base6502Opcodes, 0xE9, 0x46, 0x12, true, 0x34, true, false},
+	}
+	for _, tc := range cases {
+		carry := byte(0x18) // clc
+		if tc.carry {
+			carry = 0x38 // sec
+		}
+		c, _ := newTestCPU(t, tc.set, 0xF8, carry, 0xA9, tc.a, tc.op, tc.value)
+		step(t, c, 4)
+		if c.A != tc.result || c.flag(FlagCarry) != tc.carryOut || c.flag(FlagZero) != tc.zero {
+			t.Errorf("$%02X $%02X with $%02X gave $%02X, carry %v, zero %v", tc.op, tc.a, tc.value, c.A, c.flag(FlagCarry), c.flag(FlagZero))
+		}
+	}
+
+	c, _ := newTestCPU(t, WDC65C02Opcodes, 0xF8, 0x69, 0x01)
+	step(t, c, 1)
+	if cycles := step(t, c, 1); cycles != 3 {
+		t.Errorf("decimal adc on the 65C02 took %d cycles", cycles)
+	}
+}
+
+func TestIndirectJump(t *testing.T) {
+	for _, set := range []OpcodeSet{Base6502Opcodes, WDC65C02Opcodes} {
+		c, memory := newTestCPU(t, set, 0x6C, 0xFF, 0x10)
+		memory[0x10FF], memory[0x1100], memory[0x1000] = 0x34, 0x12, 0x56
+		step(t, c, 1)
+		expected := uint16(0x5634)
+		if c.CMOS {
+			expected = 0x1234
+		}
+		if c.PC != expected {
+			t.Errorf("jumped to $%04X, expected $%04X", c.PC, expected)
+		}
+	}
+}
+
+func TestSubroutines(t *testing.T) {
+	c, _ := newTestCPU(t, Base6502Opcodes,
+		0x20, 0x06, 0x02, // jsr $0206
+		0xA9, 0x01, // lda #1
+		0x00,       // brk
+		0xA2, 0x02, // ldx #2
+		0x60, // rts
+	)
+	if cycles := step(t, c, 4); cycles != 6+2+6+2 {
+		t.Errorf("took %d cycles", cycles)
+	}
+	if c.PC != 0x0205 || c.A != 1 || c.X != 2 || c.S != 0xFD {
+		t.Errorf("ended at $%04X with A = %d, X = %d, S = $%02X", c.PC, c.A, c.X, c.S)
+	}
+}
+
+func TestInterrupts(t *testing.T) {
+	c, memory := newTestCPU(t, WDC65C02Opcodes,
+		0x58,       // cli
+		0xCB,       // wai
+		0xEA,       // nop
+		0x00, 0x00, // brk
+	)
+	memory[0x0300] = 0x40 // rti
+	memory[0x0400] = 0x40 // rti
+
+	step(t, c, 2)
+	if !c.Waiting {
+		t.Fatalf("not waiting after wai")
+	}
+	if cycles := step(t, c, 1); cycles != 1 || c.PC != 0x0202 {
+		t.Errorf("didn't carry on waiting")
+	}
+
+	c.SetIRQ(true)
+	if cycles := step(t, c, 1); cycles != 7 || c.PC != 0x0300 {
+		t.Errorf("took %d cycles to get to $%04X", cycles, c.PC)
+	}
+	if pushed := memory[0x0100|uint16(c.S+1)]; pushed&FlagBreak != 0 {
+		t.Errorf("an IRQ pushed the break flag")
+	}
+	c.SetIRQ(false)
+	step(t, c, 1)
+	if c.PC != 0x0202 || c.flag(FlagInterrupt) {
+		t.Errorf("returned to $%04X", c.PC)
+	}
+
+	c.NMI()
+	step(t, c, 1)
+	if c.PC != 0x0400 {
+		t.Errorf("NMI went to $%04X", c.PC)
+	}
+	step(t, c, 3)
+	if c.PC != 0x0300 {
+		t.Errorf("BRK went to $%04X", c.PC)
+	}
+	if pushed := memory[0x0100|uint16(c.S+1)]; pushed&FlagBreak == 0 {
+		t.Errorf("BRK didn't push the break flag")
+	}
+	step(t, c, 1)
+	if c.PC != 0x0205 {
+		t.Errorf("returned from BRK to $%04X", c.PC)
+	}
+}
+
+func TestBitBranches(t *testing.T) {
+	c, memory := newTestCPU(t, WDC65C02Opcodes,
+		0x97, 0x10, // smb1 $10
+		0x9F, 0x10, 0x02, // bbs1 $10, +2
+		0xEA, 0xEA, // nop, nop
+		0x17, 0x10, // rmb1 $10
+	)
+	memory[0x10] = 0x80
+	step(t, c, 2)
+	if c.PC != 0x0207 || memory[0x10] != 0x82 {
+		t.Errorf("branched to $%04X with $%02X", c.PC, memory[0x10])
+	}
+	step(t, c, 1)
+	if memory[0x10] != 0x80 {
+		t.Errorf("rmb1 left $%02X", memory[0x10])
+	}
+}
+
+func TestStop(t *testing.T) {
+	c, _ := newTestCPU(t, WDC65C02Opcodes, 0xDB)
+	step(t, c, 1)
+	if _, err := c.Step(); err != ErrStopped {
+		t.Errorf("expected the CPU to be stopped, but got %v", err)
+	}
+	c.Reset()
+	if c.Stopped {
+		t.Errorf("reset didn't start the CPU again")
+	}
+}
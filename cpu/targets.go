@@ -0,0 +1,23 @@
+package cpu
+
+import "sort"
+
+// Targets are the CPUs that code can be assembled for, by the
+// names used for them on the command line and in .cpu directives
+var Targets = map[string]OpcodeSet{
+	"6502":   Base6502Opcodes,
+	"6502x":  NMOS6502ExtendedOpcodes,
+	"65c02":  WDC65C02Opcodes,
+	"r65c02": R65C02Opcodes,
+	"65c816": WDC65C816Opcodes,
+}
+
+// TargetNames returns the names of all the targets, sorted
+func TargetNames() []string {
+	names := make([]string, 0, len(Targets))
+	for name := range Targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
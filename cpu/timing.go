@@ -0,0 +1,98 @@
+package cpu
+
+import "fmt"
+
+// Penalty is a set of reasons an instruction can take more cycles than it usually does
+type Penalty byte
+
+const (
+	// one more cycle when indexing, or a branch that is always taken, crosses into another page
+	PageCross Penalty = 1 << iota
+	// one more cycle when the branch is taken, and another when it crosses into another page
+	BranchTaken
+	// one more cycle in decimal mode, on the 65C02
+	Decimal
+	// one more cycle when the register the instruction works with is 16 bits wide,
+	// or two for instructions that read, modify and write memory, on the 65C816
+	Wide
+	// one more cycle when the low byte of the direct page register isn't zero, on the 65C816
+	DirectPage
+)
+
+// MaxCycles is the most cycles the instruction can take, if every penalty applies
+func (o OpcodeData) MaxCycles() int {
+	cycles := o.Cycles
+	if o.Penalties&PageCross != 0 {
+		cycles++
+	}
+	if o.Penalties&BranchTaken != 0 {
+		cycles += 2
+	}
+	if o.Penalties&Decimal != 0 {
+		cycles++
+	}
+	if o.Penalties&Wide != 0 {
+		if readModifyWrite[o.Operation] && o.Mode != Accumulator {
+			cycles += 2
+		} else {
+			cycles++
+		}
+	}
+	if o.Penalties&DirectPage != 0 {
+		cycles++
+	}
+	return cycles
+}
+
+// CycleRange describes how many cycles the instruction takes, like "4" or "4-5"
+func (o OpcodeData) CycleRange() string {
+	if max := o.MaxCycles(); max != o.Cycles {
+		return fmt.Sprintf("%d-%d", o.Cycles, max)
+	}
+	return fmt.Sprint(o.Cycles)
+}
+
+var readModifyWrite = map[Opcode]bool{
+	ASL: true, LSR: true, ROL: true, ROR: true,
+	INC: true, DEC: true, TRB: true, TSB: true,
+}
+
+// the instructions that take longer when the accumulator is wide
+var wideAccumulatorInstructions = map[Opcode]bool{
+	ADC: true, AND: true, BIT: true, CMP: true, EOR: true, LDA: true, ORA: true, SBC: true,
+	STA: true, STZ: true, PHA: true, PLA: true,
+	ASL: true, LSR: true, ROL: true, ROR: true, INC: true, DEC: true, TRB: true, TSB: true,
+}
+
+// the instructions that take longer when the index registers are wide
+var wideIndexInstructions = map[Opcode]bool{
+	CPX: true, CPY: true, LDX: true, LDY: true, STX: true, STY: true,
+	PHX: true, PHY: true, PLX: true, PLY: true,
+}
+
+var directPageModes = map[Mode]bool{
+	ZeroPage:                     true,
+	ZeroPageIndexedX:             true,
+	ZeroPageIndexedY:             true,
+	XIndexedIndirect:             true,
+	IndirectYIndexed:             true,
+	ZeroPageIndirect:             true,
+	ZeroPageIndirectLong:         true,
+	ZeroPageIndirectLongIndexedY: true,
+}
+
+// adds the penalties the 65C816 has for wide registers and unaligned direct pages
+func with65C816Penalties(set OpcodeSet) OpcodeSet {
+	ret := make(OpcodeSet, len(set))
+	for i, op := range set {
+		wide := wideAccumulatorInstructions[op.Operation] || wideIndexInstructions[op.Operation]
+		if wide && !(readModifyWrite[op.Operation] && op.Mode == Accumulator) {
+			op.Penalties |= Wide
+		}
+		if directPageModes[op.Mode] {
+			op.Penalties |= DirectPage
+		}
+		ret[i] = op
+	}
+	return ret
+}
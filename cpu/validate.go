@@ -0,0 +1,57 @@
+package cpu
+
+import "fmt"
+
+type instruction struct {
+	Operation Opcode
+	Mode      Mode
+}
+
+// Validate checks that no two instructions in the set share an encoding,
+// and that no instruction is in the set more than once
+func (o OpcodeSet) Validate() []error {
+	var errs []error
+	byHex := map[byte]OpcodeData{}
+	byInstruction := map[instruction]OpcodeData{}
+
+	for _, op := range o {
+		if other, ok := byHex[op.Hex]; ok {
+			errs = append(errs, fmt.Errorf("$%02X is used by both %s with %s addressing and %s with %s addressing", op.Hex, other.Operation, other.Mode, op.Operation, op.Mode))
+		} else {
+			byHex[op.Hex] = op
+		}
+
+		key := instruction{op.Operation, op.Mode}
+		if other, ok := byInstruction[key]; ok {
+			errs = append(errs, fmt.Errorf("%s with %s addressing is in the set more than once, as $%02X and $%02X", op.Operation, op.Mode, other.Hex, op.Hex))
+		} else {
+			byInstruction[key] = op
+		}
+	}
+
+	return errs
+}
+
+// Compare checks the set against a reference, reporting the instructions missing from it,
+// the ones the reference doesn't have, and the ones that are encoded or timed differently
+func (o OpcodeSet) Compare(reference OpcodeSet) []error {
+	var errs []error
+
+	for _, ref := range reference {
+		op, ok := o.FindOne(ref.Operation, ref.Mode)
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s with %s addressing ($%02X) is missing", ref.Operation, ref.Mode, ref.Hex))
+		} else if op.Hex != ref.Hex {
+			errs = append(errs, fmt.Errorf("%s with %s addressing is encoded as $%02X, but should be $%02X", ref.Operation, ref.Mode, op.Hex, ref.Hex))
+		} else if op.Cycles != ref.Cycles || op.Penalties != ref.Penalties {
+			errs = append(errs, fmt.Errorf("%s with %s addressing takes %d cycles with penalties %b, but should take %d with %b", ref.Operation, ref.Mode, op.Cycles, op.Penalties, ref.Cycles, ref.Penalties))
+		}
+	}
+	for _, op := range o {
+		if _, ok := reference.FindOne(op.Operation, op.Mode); !ok {
+			errs = append(errs, fmt.Errorf("%s with %s addressing ($%02X) is not in the reference", op.Operation, op.Mode, op.Hex))
+		}
+	}
+
+	return errs
+}
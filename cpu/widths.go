@@ -0,0 +1,44 @@
+package cpu
+
+// RegisterWidths tracks the 65C816's m and x flags, which decide whether the accumulator
+// and index registers are 8 or 16 bits wide, and so how long immediate operands are.
+// Both are 8 bits wide after reset, and always are on other CPUs.
+type RegisterWidths struct {
+	WideAccumulator bool
+	WideIndex       bool
+}
+
+const (
+	flagM = 0x20
+	flagX = 0x10
+)
+
+// Update applies the effect of an instruction with the given immediate operand
+// on the register widths, which only REP and SEP have
+func (r *RegisterWidths) Update(op Opcode, operand byte) {
+	switch op {
+	case REP:
+		r.WideAccumulator = r.WideAccumulator || operand&flagM != 0
+		r.WideIndex = r.WideIndex || operand&flagX != 0
+	case SEP:
+		r.WideAccumulator = r.WideAccumulator && operand&flagM == 0
+		r.WideIndex = r.WideIndex && operand&flagX == 0
+	}
+}
+
+// OperandSize is the number of bytes following the instruction's opcode with these register widths
+func (r RegisterWidths) OperandSize(op OpcodeData) int {
+	if op.Mode == Immediate {
+		switch op.Operation {
+		case ADC, AND, BIT, CMP, EOR, LDA, ORA, SBC:
+			if r.WideAccumulator {
+				return 2
+			}
+		case CPX, CPY, LDX, LDY:
+			if r.WideIndex {
+				return 2
+			}
+		}
+	}
+	return op.Mode.OperandSize()
+}
@@ -0,0 +1,169 @@
+// Package diagnostic describes problems found in source files, and shows them
+// either with the lines they're about or as JSON for editors to read
+package diagnostic
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// Severity is how bad a problem is
+type Severity int
+
+const (
+	Error Severity = iota
+	Warning
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Error:
+		return "error"
+	case Warning:
+		return "warning"
+	default:
+		panic("unhandled case")
+	}
+}
+
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Label is a part of a file that a diagnostic is about, from the start up to but not
+// including the end. The end is ignored if it's before the start, and a line of 0
+// means that the label isn't anywhere in particular.
+type Label struct {
+	Filename  string `json:"filename"`
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+	EndLine   int    `json:"endLine"`
+	EndColumn int    `json:"endColumn"`
+	// what this part has to do with the problem, which is often empty for the primary label
+	Message string `json:"message,omitempty"`
+}
+
+// Diagnostic is a problem found in one or more files
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+	// Code identifies the kind of problem, and doesn't change between versions
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Primary is where the problem is
+	Primary Label `json:"primary"`
+	// Secondary are other places that explain it, like where something was first declared
+	Secondary []Label `json:"secondary,omitempty"`
+}
+
+// Render writes a diagnostic along with the lines it's about, underlining the
+// primary label with ^ and the others with -. Labels in files that aren't in
+// sources are written without their lines.
+func Render(w io.Writer, d Diagnostic, sources map[string][]byte) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s[%s]: %s\n", d.Severity, d.Code, d.Message)
+
+	labels := append([]Label{d.Primary}, d.Secondary...)
+	width := 0
+	for _, l := range labels {
+		width = max(width, len(fmt.Sprint(l.Line)))
+	}
+	gutter := strings.Repeat(" ", width)
+	for i, l := range labels {
+		marker := '-'
+		if i == 0 {
+			marker = '^'
+		}
+		if l.Line == 0 {
+			// it isn't anywhere in particular
+			continue
+		}
+		fmt.Fprintf(&b, "%s--> %s\n", gutter, location(l))
+		line, ok := sourceLine(sources, l)
+		if !ok {
+			if l.Message != "" {
+				fmt.Fprintf(&b, "%s = %s\n", gutter, l.Message)
+			}
+			continue
+		}
+		fmt.Fprintf(&b, "%s |\n", gutter)
+		fmt.Fprintf(&b, "%*d | %s\n", width, l.Line, string(line))
+		fmt.Fprintf(&b, "%s | %s\n", gutter, strings.TrimRight(underline(line, l, marker)+" "+l.Message, " "))
+	}
+	b.WriteString("\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteJSON writes the diagnostics as a JSON array
+func WriteJSON(w io.Writer, diagnostics []Diagnostic) error {
+	if diagnostics == nil {
+		diagnostics = []Diagnostic{}
+	}
+	return json.NewEncoder(w).Encode(diagnostics)
+}
+
+func location(l Label) string {
+	if l.Filename == "" {
+		return fmt.Sprintf("%d:%d", l.Line, l.Column)
+	}
+	return fmt.Sprintf("%s:%d:%d", l.Filename, l.Line, l.Column)
+}
+
+func sourceLine(sources map[string][]byte, l Label) ([]rune, bool) {
+	text, ok := sources[l.Filename]
+	if !ok || l.Line < 1 {
+		return nil, false
+	}
+	lines := strings.Split(string(text), "\n")
+	if l.Line > len(lines) {
+		return nil, false
+	}
+	return []rune(strings.TrimRight(lines[l.Line-1], "\r")), true
+}
+
+// the marker under the label's part of the line, keeping tabs
+// before it so that it lines up however wide they're shown
+func underline(line []rune, l Label, marker rune) string {
+	start := min(max(l.Column-1, 0), len(line))
+	// up to the end of the label, or the end of the line if it carries on past it.
+	// Labels without an end are only marked where they start.
+	end := len(line)
+	switch {
+	case l.EndLine == 0:
+		end = min(start+1, len(line))
+	case l.EndLine == l.Line && l.EndColumn > l.Column:
+		end = min(l.EndColumn-1, len(line))
+	}
+	for end > start && unicode.IsSpace(line[end-1]) {
+		end--
+	}
+
+	var b strings.Builder
+	for _, c := range line[:start] {
+		if c == '\t' {
+			b.WriteRune('\t')
+		} else {
+			b.WriteRune(' ')
+		}
+	}
+	b.WriteString(strings.Repeat(string(marker), max(end-start, 1)))
+	return b.String()
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,84 @@
+package diagnostic
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+var source = map[string][]byte{
+	"main.san": []byte("@main {\n\t&loop:\n\tlda #1;\n\t&loop:\n}\n"),
+}
+
+func TestRender(t *testing.T) {
+	d := Diagnostic{
+		Severity: Error,
+		Code:     "E104",
+		Message:  "Duplicate symbol 'loop'",
+		Primary:  Label{Filename: "main.san", Line: 4, Column: 2, EndLine: 4, EndColumn: 8},
+		Secondary: []Label{
+			{Filename: "main.san", Line: 2, Column: 2, EndLine: 3, EndColumn: 2, Message: "first declared"},
+			{Filename: "other.san", Line: 10, Column: 1, Message: "not shown"},
+		},
+	}
+	var b bytes.Buffer
+	if err := Render(&b, d, source); err != nil {
+		t.Fatal(err)
+	}
+	expected := `error[E104]: Duplicate symbol 'loop'
+  --> main.san:4:2
+   |
+ 4 | 	&loop:
+   | 	^^^^^^
+  --> main.san:2:2
+   |
+ 2 | 	&loop:
+   | 	------ first declared
+  --> other.san:10:1
+   = not shown
+
+`
+	if b.String() != expected {
+		t.Fatalf("got\n%s\nexpected\n%s", b.String(), expected)
+	}
+}
+
+func TestRenderWithoutAnEnd(t *testing.T) {
+	d := Diagnostic{
+		Severity: Warning,
+		Code:     "E001",
+		Message:  "unexpected token",
+		Primary:  Label{Filename: "main.san", Line: 3, Column: 6},
+	}
+	var b bytes.Buffer
+	if err := Render(&b, d, source); err != nil {
+		t.Fatal(err)
+	}
+	expected := "warning[E001]: unexpected token\n --> main.san:3:6\n  |\n3 | \tlda #1;\n  | \t    ^\n\n"
+	if b.String() != expected {
+		t.Fatalf("got %q, expected %q", b.String(), expected)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var b bytes.Buffer
+	d := Diagnostic{Severity: Error, Code: "E200", Message: "youre missing a main fragment"}
+	if err := WriteJSON(&b, []Diagnostic{d}); err != nil {
+		t.Fatal(err)
+	}
+	var got []map[string]interface{}
+	if err := json.Unmarshal(b.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0]["severity"] != "error" || got[0]["code"] != "E200" || got[0]["message"] != d.Message {
+		t.Fatalf("unexpected JSON %s", b.String())
+	}
+
+	b.Reset()
+	if err := WriteJSON(&b, nil); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != "[]\n" {
+		t.Fatalf("expected an empty array, but got %q", b.String())
+	}
+}
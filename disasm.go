@@ -0,0 +1,204 @@
+package main
+
+import (
+	"Sano/cpu"
+	"Sano/linker"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+type label struct {
+	name string
+	// whether the label starts a fragment, or is a subsymbol inside one
+	fragment bool
+}
+
+type disassembledLine struct {
+	address     uint16
+	instruction *cpu.Instruction
+	bad         []byte
+	err         error
+}
+
+// writes code loaded at origin as sano source, using labels from
+// symbols and inventing labels for jump and branch targets
+func writeDisassembly(w io.Writer, set cpu.OpcodeSet, code []byte, origin, entry uint16, symbols linker.SymbolTable, cycles bool) error {
+	var lines []disassembledLine
+	boundaries := map[uint16]bool{}
+	// only followed in a straight line through the code
+	widths := cpu.RegisterWidths{}
+	for offset := 0; offset < len(code); {
+		address := origin + uint16(offset)
+		boundaries[address] = true
+		inst, err := set.DisassembleWith(code[offset:], address, widths)
+		if err != nil {
+			lines = append(lines, disassembledLine{address: address, bad: code[offset : offset+1], err: err})
+			offset++
+			continue
+		}
+		lines = append(lines, disassembledLine{address: address, instruction: &inst})
+		if inst.Operation == cpu.REP || inst.Operation == cpu.SEP {
+			widths.Update(inst.Operation, inst.Operand[0])
+		}
+		offset += inst.Size()
+	}
+
+	labels := map[uint16]label{}
+	used := map[string]bool{}
+	addLabel := func(address uint16, l label) {
+		if _, ok := labels[address]; ok || !boundaries[address] || (l.fragment && used[l.name]) {
+			return
+		}
+		labels[address] = l
+		if l.fragment {
+			used[l.name] = true
+		}
+	}
+
+	addLabel(entry, label{"main", true})
+	for address, names := range symbols.ByAddress() {
+		for _, name := range names {
+			if i := strings.LastIndex(name, "/"); i >= 0 {
+				addLabel(address, label{name[i+1:], false})
+			} else {
+				addLabel(address, label{name, true})
+			}
+		}
+	}
+	if l, ok := labels[origin]; ok {
+		l.fragment = true
+		labels[origin] = l
+	} else {
+		addLabel(origin, label{fmt.Sprintf("F%04X", origin), true})
+	}
+	for _, line := range lines {
+		inst := line.instruction
+		if inst == nil {
+			continue
+		}
+		switch {
+		case inst.Mode == cpu.Relative || inst.Mode == cpu.ZeroPageRelative:
+			addLabel(inst.Target(), label{fmt.Sprintf("L%04X", inst.Target()), false})
+		case inst.Operation == cpu.JSR:
+			addLabel(uint16(inst.Value()), label{fmt.Sprintf("F%04X", inst.Value()), true})
+		case inst.Operation == cpu.JMP && inst.Mode == cpu.Absolute:
+			addLabel(uint16(inst.Value()), label{fmt.Sprintf("L%04X", inst.Value()), false})
+		}
+	}
+
+	// which fragment every address is in, so that we know
+	// which subsymbols can be referred to from where
+	var starts []uint16
+	for address, l := range labels {
+		if l.fragment {
+			starts = append(starts, address)
+		}
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i] < starts[j] })
+	fragmentOf := func(address uint16) uint16 {
+		i := sort.Search(len(starts), func(i int) bool { return starts[i] > address })
+		if i == 0 {
+			return origin
+		}
+		return starts[i-1]
+	}
+	// subsymbols only have to be unique within their fragment, and one
+	// whose name is already taken there is numbered instead of dropped
+	var addresses []uint16
+	for address := range labels {
+		addresses = append(addresses, address)
+	}
+	sort.Slice(addresses, func(i, j int) bool { return addresses[i] < addresses[j] })
+	for _, address := range addresses {
+		l := labels[address]
+		if l.fragment {
+			continue
+		}
+		scope := fmt.Sprintf("%04X/", fragmentOf(address))
+		name := l.name
+		for n := 2; used[name] || used[scope+name]; n++ {
+			name = fmt.Sprintf("%s_%d", l.name, n)
+		}
+		used[scope+name] = true
+		l.name = name
+		labels[address] = l
+	}
+	labelFor := func(from, address uint16) (string, bool) {
+		l, ok := labels[address]
+		if !ok || (!l.fragment && fragmentOf(from) != fragmentOf(address)) {
+			return "", false
+		}
+		return l.name, true
+	}
+
+	for i, line := range lines {
+		if l, ok := labels[line.address]; ok {
+			if l.fragment {
+				if i > 0 {
+					fmt.Fprintf(w, "}\n\n")
+				}
+				fmt.Fprintf(w, "@%s {\n", l.name)
+			} else {
+				fmt.Fprintf(w, "\t&%s:\n", l.name)
+			}
+		}
+
+		if line.instruction == nil {
+			fmt.Fprintf(w, "\t// 0x%02X: %s\n", line.bad[0], line.err)
+			continue
+		}
+
+		inst := line.instruction
+		number := func(value uint32, size int) string {
+			return fmt.Sprintf("0x%0*X", size*2, value)
+		}
+		address := func(value uint32, size int) string {
+			if name, ok := labelFor(inst.Address, uint16(value)); ok && value <= 0xFFFF {
+				return name
+			}
+			return number(value, size)
+		}
+		branch := func() string {
+			if name, ok := labelFor(inst.Address, inst.Target()); ok {
+				return name
+			}
+			if inst.Mode == cpu.RelativeLong {
+				return number(inst.Value(), 2)
+			}
+			// backwards branches are written as negative, since offsets that don't fit in a byte
+			// as signed numbers don't assemble
+			offset := int8(inst.Operand[len(inst.Operand)-1])
+			if offset < 0 {
+				return fmt.Sprintf("(%d)", offset)
+			}
+			return number(uint32(offset), 1)
+		}
+
+		var operands []string
+		switch inst.Mode {
+		case cpu.Implied, cpu.Accumulator:
+		case cpu.Relative, cpu.RelativeLong:
+			operands = []string{branch()}
+		case cpu.ZeroPageRelative:
+			operands = []string{address(uint32(inst.Operand[0]), 1), branch()}
+		case cpu.BlockMove:
+			operands = []string{number(uint32(inst.Operand[0]), 1), number(uint32(inst.Operand[1]), 1)}
+		case cpu.Immediate, cpu.StackRelative, cpu.StackRelativeIndirectIndexedY:
+			operands = []string{number(inst.Value(), len(inst.Operand))}
+		default:
+			operands = []string{address(inst.Value(), len(inst.Operand))}
+		}
+		if cycles {
+			fmt.Fprintf(w, "\t%s %s; // %s cycles\n", inst.Operation, inst.Mode.Syntax(operands...), inst.CycleRange())
+		} else {
+			fmt.Fprintf(w, "\t%s %s;\n", inst.Operation, inst.Mode.Syntax(operands...))
+		}
+	}
+	if len(lines) > 0 {
+		fmt.Fprintln(w, "}")
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"Sano/compiler"
+	"Sano/cpu"
+	"Sano/linker"
+	"Sano/parser"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const disasmSource = `@main {
+	ldx #0x05;
+	&loop:
+	jsr =print;
+	dex !;
+	bne ~loop;
+	rts !;
+}
+
+@print {
+	lda #0x41;
+	&again:
+	sta =0x9F23;
+	bne ~again;
+	rts !;
+}
+`
+
+func assemble(t *testing.T, source string, origin uint16) *linker.Layout {
+	t.Helper()
+
+	f, err := parser.Parser.ParseString("test.san", source)
+	if err != nil {
+		t.Fatalf("failed to parse\n%s\n%s", source, err)
+	}
+	c := compiler.Compiler{Instructions: cpu.WDC65C02Opcodes}
+	obj, errs := c.Compile(f)
+	for _, err := range errs {
+		t.Fatalf("failed to compile\n%s\n%s", source, err.String())
+	}
+	layout, err := linker.Link([]*linker.Object{obj}, origin)
+	if err != nil {
+		t.Fatalf("failed to link: %s", err)
+	}
+	return layout
+}
+
+func TestDisassemblyWithSymbolsRoundTrips(t *testing.T) {
+	const origin = 0x1000
+	layout := assemble(t, disasmSource, origin)
+
+	var source strings.Builder
+	if err := writeDisassembly(&source, cpu.WDC65C02Opcodes, layout.Code, origin, origin, layout.Symbols, false); err != nil {
+		t.Fatal(err)
+	}
+	if source.String() != disasmSource {
+		t.Fatalf("disassembled to\n%s\nexpected\n%s", source.String(), disasmSource)
+	}
+}
+
+func TestSubsymbolsWithTheSameNameRoundTrip(t *testing.T) {
+	const origin = 0x1000
+	const source = `@main {
+	ldx #0x05;
+	&loop:
+	jsr =print;
+	dex !;
+	bne ~loop;
+	rts !;
+}
+
+@print {
+	ldy #0x03;
+	&loop:
+	sta =0x9F23;
+	dey !;
+	bne ~loop;
+	rts !;
+}
+`
+	layout := assemble(t, source, origin)
+
+	var got strings.Builder
+	if err := writeDisassembly(&got, cpu.WDC65C02Opcodes, layout.Code, origin, origin, layout.Symbols, false); err != nil {
+		t.Fatal(err)
+	}
+	if got.String() != source {
+		t.Fatalf("disassembled to\n%s\nexpected\n%s", got.String(), source)
+	}
+}
+
+func TestTakenSubsymbolNamesAreNumbered(t *testing.T) {
+	const origin = 0x1000
+	// print has a subsymbol with the same name as a fragment
+	code := []byte{0xA2, 0x05, 0xCA, 0xD0, 0xFD, 0x20, 0x09, 0x10, 0x60, 0xA0, 0x03, 0x88, 0xD0, 0xFD, 0x60}
+	symbols := linker.SymbolTable{"main": origin, "main/loop": origin + 2, "print": origin + 9, "print/main": origin + 11}
+
+	var source strings.Builder
+	if err := writeDisassembly(&source, cpu.WDC65C02Opcodes, code, origin, origin, symbols, false); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(source.String(), "&main_2:") || !strings.Contains(source.String(), "bne ~main_2;") {
+		t.Errorf("expected the subsymbol named like a fragment to be numbered, but got\n%s", source.String())
+	}
+	if got := assemble(t, source.String(), origin).Code; !bytes.Equal(got, code) {
+		t.Fatalf("reassembled\n%s\nto % X, expected % X", source.String(), got, code)
+	}
+}
+
+func TestDisassemblyWithoutSymbolsReassembles(t *testing.T) {
+	const origin = 0x1000
+	code := assemble(t, disasmSource, origin).Code
+
+	var source strings.Builder
+	if err := writeDisassembly(&source, cpu.WDC65C02Opcodes, code, origin, origin, nil, false); err != nil {
+		t.Fatal(err)
+	}
+	if got := assemble(t, source.String(), origin).Code; !bytes.Equal(got, code) {
+		t.Fatalf("reassembled\n%s\nto % X, expected % X", source.String(), got, code)
+	}
+}
+
+func TestBranchesIntoAnotherFragmentReassemble(t *testing.T) {
+	const origin = 0x1000
+	// main calls a fragment that branches back into main's body, which
+	// there's no label for from the second fragment
+	code := []byte{0xA2, 0x05, 0xCA, 0x20, 0x07, 0x10, 0x60, 0xD0, 0xF9, 0x60}
+
+	var source strings.Builder
+	if err := writeDisassembly(&source, cpu.WDC65C02Opcodes, code, origin, origin, nil, false); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(source.String(), "bne ~(-7);") {
+		t.Errorf("expected the branch back to be written with a negative offset, but got\n%s", source.String())
+	}
+	if got := assemble(t, source.String(), origin).Code; !bytes.Equal(got, code) {
+		t.Fatalf("reassembled to % X, expected % X", got, code)
+	}
+}
@@ -0,0 +1,162 @@
+package linker
+
+import (
+	"Sano/cpu"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+func formatBytes(b []byte) string {
+	s := make([]string, len(b))
+	for i, v := range b {
+		s[i] = fmt.Sprintf("%02X", v)
+	}
+	return strings.Join(s, " ")
+}
+
+func formatSpan(s *Span) string {
+	if s == nil || s.Start == nil {
+		return ""
+	}
+	return FormatPosition(s.Start)
+}
+
+func (s SymbolSize) describe() string {
+	switch s {
+	case SymbolSize_WORD:
+		return "word"
+	case SymbolSize_BYTE:
+		return "byte"
+	case SymbolSize_RELATIVE:
+		return "relative"
+	case SymbolSize_LONG:
+		return "long"
+	case SymbolSize_RELATIVE_LONG:
+		return "relative long"
+	default:
+		panic("unhandled case")
+	}
+}
+
+func formatNumber(b []byte) string {
+	s := "0x"
+	for i := len(b) - 1; i >= 0; i-- {
+		s += fmt.Sprintf("%02X", b[i])
+	}
+	return s
+}
+
+// disassembles the instruction starting with the first byte of rest[0],
+// which must be a literal, using what follows it for the operands
+func disassemble(rest []*Expression, set cpu.OpcodeSet) (string, int) {
+	lit := rest[0].Inner.(*Expression_Literal_).Literal.Value
+	op, ok := set.Decode(lit[0])
+	if !ok {
+		return fmt.Sprintf("??? ; unknown opcode 0x%02X", lit[0]), 0
+	}
+
+	var operands []string
+	inline, next := lit[1:], rest[1:]
+	sizes := op.Mode.OperandSizes()
+	for i, size := range sizes {
+		if len(inline) == 0 && len(next) > 0 {
+			switch t := next[0].Inner.(type) {
+			case *Expression_Literal_:
+				inline = t.Literal.Value
+			case *Expression_Symbol_:
+				operands = append(operands, t.Symbol.Name)
+				next = next[1:]
+				continue
+			}
+			next = next[1:]
+		}
+		// immediate operands can be wider than usual on the 65C816
+		if i == len(sizes)-1 && op.Mode == cpu.Immediate && len(inline) == 2 {
+			size = 2
+		}
+		if len(inline) < size {
+			return fmt.Sprintf("%s ; operand missing", op.Operation), 0
+		}
+		operands = append(operands, formatNumber(inline[:size]))
+		inline = inline[size:]
+	}
+
+	return fmt.Sprintf("%s %s", op.Operation, op.Mode.Syntax(operands...)), op.Mode.OperandSize()
+}
+
+// Dump writes a human-readable description of an object: its fragments,
+// their expressions, and a disassembly of the code in them
+func Dump(w io.Writer, o *Object, set cpu.OpcodeSet) error {
+	keys := make([]string, 0, len(o.Fragments))
+	for key := range o.Fragments {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for i, key := range keys {
+		frag := o.Fragments[key]
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "@%s (%s) %s\n", key, frag.Symbol, formatSpan(frag.Span))
+		if frag.Segment == Segment_ZERO_PAGE {
+			fmt.Fprintf(w, "  %d bytes of zero page\n", frag.Reserve)
+			continue
+		}
+
+		tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+		offset := 0
+		// how many bytes of operand are still expected for the last instruction
+		pending := 0
+		for j, expr := range frag.Expressions {
+			var contents, disassembly string
+			// before linking, every choice is as big as it could be
+			size, _ := expressionSize(expr, nil)
+
+			switch t := expr.Inner.(type) {
+			case *Expression_Literal_:
+				contents = formatBytes(t.Literal.Value)
+				if expr.Data {
+					disassembly = "data"
+					pending = 0
+				} else if pending == 0 && len(t.Literal.Value) > 0 {
+					disassembly, pending = disassemble(frag.Expressions[j:], set)
+					pending -= len(t.Literal.Value) - 1
+				} else {
+					pending -= len(t.Literal.Value)
+				}
+			case *Expression_Symbol_:
+				contents = fmt.Sprintf("%s %s", t.Symbol.Size.describe(), t.Symbol.Name)
+				pending -= size
+			case *Expression_Subsymbol_:
+				contents = t.Subsymbol.Name + ":"
+			case *Expression_ZeroPageChoice_:
+				contents = fmt.Sprintf("%02X or %02X %s", t.ZeroPageChoice.ZeroPage, t.ZeroPageChoice.Absolute, t.ZeroPageChoice.Name)
+				if op, ok := set.Decode(byte(t.ZeroPageChoice.Absolute)); ok {
+					disassembly = fmt.Sprintf("%s %s", op.Operation, strings.TrimPrefix(op.Mode.Syntax(t.ZeroPageChoice.Name), "="))
+				}
+				pending = 0
+			case *Expression_Unary_:
+				contents = fmt.Sprintf("unary %s", t.Unary.Kind)
+			default:
+				panic("unhandled case")
+			}
+			if pending < 0 {
+				pending = 0
+			}
+
+			fmt.Fprintf(tw, "  %04X\t%s\t%s\t%s\n", offset, contents, disassembly, formatSpan(expr.Span))
+			offset += size
+		}
+		err := tw.Flush()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "  %d bytes\n", offset)
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+// an external test, so that the object can come from the compiler
+package linker_test
+
+import (
+	"Sano/compiler"
+	"Sano/cpu"
+	"Sano/linker"
+	"Sano/parser"
+	"strings"
+	"testing"
+)
+
+func TestDumpingAnObject(t *testing.T) {
+	f, err := parser.Parser.ParseString("test.san", `@counter zeropage 1;
+@main {
+	ldx #0x05;
+	&loop:
+	jsr =print;
+	dex !;
+	bne ~loop;
+	rts !;
+}
+
+@print {
+	inc counter;
+	ldy :counter;
+	sta =0x9F23;
+	.byte 1, 2;
+	rts !;
+}`)
+	if err != nil {
+		t.Fatalf("failed to parse: %s", err)
+	}
+	c := compiler.Compiler{Instructions: cpu.WDC65C02Opcodes}
+	obj, errs := c.Compile(f)
+	if len(errs) > 0 {
+		t.Fatalf("failed to compile: %s", errs[0].String())
+	}
+
+	var out strings.Builder
+	if err := linker.Dump(&out, obj, cpu.WDC65C02Opcodes); err != nil {
+		t.Fatal(err)
+	}
+	expected := `@counter (counter) test.san:1:1
+  1 bytes of zero page
+
+@main (main) test.san:2:1
+  0000  A2                  ldx #0x05       test.san:3:2
+  0001  05                                  test.san:3:7
+  0002  main/loop:                          test.san:4:2
+  0002  20                  jsr =print      test.san:5:2
+  0003  word print                          test.san:5:7
+  0005  CA                  dex !           test.san:6:2
+  0006  D0                  bne ~main/loop  test.san:7:2
+  0007  relative main/loop                  test.san:7:7
+  0008  60                  rts !           test.san:8:2
+  9 bytes
+
+@print (print) test.san:11:1
+  0000  E6 or EE counter  inc counter   test.san:12:2
+  0003  A4                ldy :counter  test.san:13:2
+  0004  byte counter                    test.san:13:7
+  0005  8D                sta =0x9F23   test.san:14:2
+  0006  23 9F                           test.san:14:7
+  0008  01                data          test.san:15:8
+  0009  02                data          test.san:15:11
+  000A  60                rts !         test.san:16:2
+  11 bytes
+`
+	if got := out.String(); got != expected {
+		t.Fatalf("dumped\n%s\nexpected\n%s", got, expected)
+	}
+}
@@ -0,0 +1,111 @@
+package linker
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LineTable maps ranges of addresses to the source lines they were compiled from,
+// sorted by address
+//
+// Line tables are written as text, so that they're easy to consume from debuggers
+// and scripts. The first line is the header:
+//
+//	sano lines 1
+//
+// followed by one line per range of addresses:
+//
+//	<start> <end> <line> <column> <offset> <filename>
+//
+// where start and end are hexadecimal addresses with end being exclusive, so that it's
+// 10000 for a range that runs to the end of memory. The ranges are in order of address
+// and don't overlap. Line, column, and offset are decimal, and the filename is the rest
+// of the line.
+type LineTable []Line
+
+const lineTableHeader = "sano lines 1"
+
+// Lookup finds the source position of the code at the given address
+func (t LineTable) Lookup(address uint16) (*Position, bool) {
+	i := sort.Search(len(t), func(i int) bool {
+		return t[i].End > uint32(address)
+	})
+	if i == len(t) || t[i].Start > address {
+		return nil, false
+	}
+	return t[i].Position, true
+}
+
+func WriteLineTable(w io.Writer, t LineTable) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, lineTableHeader)
+	for _, l := range t {
+		if strings.Contains(l.Position.Filename, "\n") {
+			return fmt.Errorf("filename %q cannot be stored in a line table", l.Position.Filename)
+		}
+		fmt.Fprintf(bw, "%04X %04X %d %d %d %s\n", l.Start, l.End, l.Position.Line, l.Position.Column, l.Position.Offset, l.Position.Filename)
+	}
+	return bw.Flush()
+}
+
+func ReadLineTable(r io.Reader) (LineTable, error) {
+	scanner := bufio.NewScanner(r)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("line table is empty")
+	}
+	if scanner.Text() != lineTableHeader {
+		return nil, fmt.Errorf("not a line table, expected header %q but got %q", lineTableHeader, scanner.Text())
+	}
+
+	var t LineTable
+	for n := 2; scanner.Scan(); n++ {
+		fields := strings.SplitN(scanner.Text(), " ", 6)
+		if len(fields) != 6 {
+			return nil, fmt.Errorf("line %d: expected 6 fields, but got %d", n, len(fields))
+		}
+
+		start, err := strconv.ParseUint(fields[0], 16, 16)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: bad start address: %w", n, err)
+		}
+		end, err := strconv.ParseUint(fields[1], 16, 32)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: bad end address: %w", n, err)
+		}
+		if end <= start || end > 0x10000 {
+			return nil, fmt.Errorf("line %d: the range %04X to %04X is empty or past the end of memory", n, start, end)
+		}
+		if len(t) > 0 && uint32(start) < t[len(t)-1].End {
+			return nil, fmt.Errorf("line %d: the range starting at %04X overlaps or comes before the one on the line before it", n, start)
+		}
+		var pos [3]int64
+		for i := range pos {
+			pos[i], err = strconv.ParseInt(fields[i+2], 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: bad source position: %w", n, err)
+			}
+		}
+
+		t = append(t, Line{
+			Start: uint16(start),
+			End:   uint32(end),
+			Position: &Position{
+				Filename: fields[5],
+				Line:     int32(pos[0]),
+				Column:   int32(pos[1]),
+				Offset:   int32(pos[2]),
+			},
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
@@ -0,0 +1,100 @@
+package linker
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestLineTableRoundTrip(t *testing.T) {
+	table := LineTable{
+		{Start: 0x0801, End: 0x0803, Position: &Position{Filename: "main.san", Line: 1, Column: 9, Offset: 8}},
+		{Start: 0x0803, End: 0x0806, Position: &Position{Filename: "lib/my file.san", Line: 12, Column: 2, Offset: 140}},
+		{Start: 0x0806, End: 0x0807, Position: &Position{Filename: "main.san", Line: 3, Column: 1, Offset: 30}},
+		// all the way up to the end of memory
+		{Start: 0xFFFA, End: 0x10000, Position: &Position{Filename: "vectors.san", Line: 2, Column: 2, Offset: 12}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteLineTable(&buf, table); err != nil {
+		t.Fatal(err)
+	}
+	expected := "sano lines 1\n" +
+		"0801 0803 1 9 8 main.san\n" +
+		"0803 0806 12 2 140 lib/my file.san\n" +
+		"0806 0807 3 1 30 main.san\n" +
+		"FFFA 10000 2 2 12 vectors.san\n"
+	if buf.String() != expected {
+		t.Fatalf("wrote %q, expected %q", buf.String(), expected)
+	}
+
+	got, err := ReadLineTable(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(table) {
+		t.Fatalf("read %d lines, expected %d", len(got), len(table))
+	}
+	for i := range table {
+		if got[i].Start != table[i].Start || got[i].End != table[i].End || !proto.Equal(got[i].Position, table[i].Position) {
+			t.Errorf("line %d: read %v, expected %v", i, got[i], table[i])
+		}
+	}
+
+	if pos, ok := got.Lookup(0x0804); !ok || pos.Filename != "lib/my file.san" {
+		t.Errorf("looked up %v, expected lib/my file.san", pos)
+	}
+	if pos, ok := got.Lookup(0xFFFF); !ok || pos.Filename != "vectors.san" {
+		t.Errorf("looked up %v, expected vectors.san", pos)
+	}
+	if pos, ok := got.Lookup(0x0900); ok {
+		t.Errorf("looked up %v, expected nothing", pos)
+	}
+}
+
+func TestNewlinesInFilenamesCantBeWritten(t *testing.T) {
+	table := LineTable{{Start: 0x0801, End: 0x0802, Position: &Position{Filename: "a\nb.san"}}}
+	if err := WriteLineTable(&bytes.Buffer{}, table); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestBadLineTables(t *testing.T) {
+	cases := map[string]string{
+		"":                                     "line table is empty",
+		"sano lines 2\n":                       "not a line table",
+		"0801 0803 1 9 8 main.san\n":           "not a line table",
+		"sano lines 1\n0801 0803 1 9\n":        "line 2: expected 6 fields, but got 4",
+		"sano lines 1\nXYZ 0803 1 9 8 a.san\n": "line 2: bad start address",
+		"sano lines 1\n0801 XYZ 1 9 8 a.san\n": "line 2: bad end address",
+		"sano lines 1\n0801 0803 1 9 8 a.san\n0803 0804 one 9 8 a.san\n": "line 3: bad source position",
+		// ranges that Lookup would give the wrong answers for
+		"sano lines 1\n0803 0803 1 9 8 a.san\n":                        "line 2: the range 0803 to 0803 is empty",
+		"sano lines 1\n0803 0801 1 9 8 a.san\n":                        "line 2: the range 0803 to 0801 is empty",
+		"sano lines 1\nFFFF 10001 1 9 8 a.san\n":                       "past the end of memory",
+		"sano lines 1\n0801 0804 1 9 8 a.san\n0803 0806 2 1 9 a.san\n": "line 3: the range starting at 0803 overlaps",
+		"sano lines 1\n0803 0806 1 9 8 a.san\n0801 0803 2 1 9 a.san\n": "line 3: the range starting at 0801 overlaps or comes before",
+	}
+	for text, expected := range cases {
+		_, err := ReadLineTable(strings.NewReader(text))
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("%q: expected an error containing %q, but got %v", text, expected, err)
+		}
+	}
+}
+
+func TestLinesRunToTheEndOfMemory(t *testing.T) {
+	span := &Span{Start: &Position{Filename: "vectors.san", Line: 2}}
+	table := lines([]Placement{
+		{Expression: &Expression{Span: span}, Address: 0xFFFC, Bytes: []byte{0x00, 0x10}},
+		{Expression: &Expression{Span: span}, Address: 0xFFFE, Bytes: []byte{0x00, 0x10}},
+	})
+	if len(table) != 1 || table[0].Start != 0xFFFC || table[0].End != 0x10000 {
+		t.Fatalf("got %v, expected one line from FFFC to 10000", table)
+	}
+	if _, ok := table.Lookup(0xFFFF); !ok {
+		t.Errorf("expected FFFF to be in the line table")
+	}
+}
@@ -1,12 +1,15 @@
 package linker
 
 import (
+	"Sano/diagnostic"
 	"bytes"
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"io"
-
-	"google.golang.org/protobuf/encoding/protojson"
+	"sort"
+	"strconv"
+	"strings"
 )
 
 // must not have duplicate symbols
@@ -17,6 +20,7 @@ func Concatenate(os []*Object) *Object {
 		for key, frag := range o.Fragments {
 			total.Fragments[key] = frag
 		}
+		total.Tests = append(total.Tests, o.Tests...)
 	}
 	return total
 }
@@ -32,45 +36,544 @@ func WriteUint16(w io.Writer, u uint16) (int, error) {
 	return w.Write(b[:])
 }
 
-// returns a prg file
-func LinkToPrg(o []*Object) ([]byte, error) {
-	bigly := Concatenate(o)
+// LinkError is a problem found while linking, located at the
+// source that caused it where that is known
+type LinkError struct {
+	Message string
+	Span    *Span
+}
 
-	main, ok := bigly.Fragments["main"]
-	if !ok {
-		return nil, errors.New("youre missing a main fragment")
+func (e *LinkError) Error() string {
+	if e.Span == nil || e.Span.Start == nil {
+		return e.Message
 	}
+	return fmt.Sprintf("%s: %s", FormatPosition(e.Span.Start), e.Message)
+}
 
-	println(protojson.MarshalOptions{Indent: "  "}.Format(main))
+// The diagnostic codes of every LinkError and every UnusedFragment
+const (
+	CodeLink   = "E200"
+	CodeUnused = "W200"
+)
 
-	var b bytes.Buffer
+// the label of a span, which is empty if where it is isn't known
+func spanLabel(s *Span) diagnostic.Label {
+	if s == nil || s.Start == nil {
+		return diagnostic.Label{}
+	}
+	l := diagnostic.Label{Filename: s.Start.Filename, Line: int(s.Start.Line), Column: int(s.Start.Column)}
+	if s.End != nil {
+		l.EndLine, l.EndColumn = int(s.End.Line), int(s.End.Column)
+	}
+	return l
+}
+
+// Diagnostic describes the error for showing with the source it's about
+func (e *LinkError) Diagnostic() diagnostic.Diagnostic {
+	return diagnostic.Diagnostic{Severity: diagnostic.Error, Code: CodeLink, Message: e.Message, Primary: spanLabel(e.Span)}
+}
+
+// LinkErrors is every problem found while linking
+type LinkErrors []*LinkError
+
+func (e LinkErrors) Diagnostics() []diagnostic.Diagnostic {
+	ret := make([]diagnostic.Diagnostic, len(e))
+	for i, err := range e {
+		ret[i] = err.Diagnostic()
+	}
+	return ret
+}
+
+func (e LinkErrors) Error() string {
+	s := make([]string, len(e))
+	for i, err := range e {
+		s[i] = err.Error()
+	}
+	return strings.Join(s, "\n")
+}
+
+// FormatPosition formats a position the same way participle's
+// lexer.Position does, as file:line:column
+func FormatPosition(p *Position) string {
+	if p.Filename == "" {
+		return fmt.Sprintf("%d:%d", p.Line, p.Column)
+	}
+	return fmt.Sprintf("%s:%d:%d", p.Filename, p.Line, p.Column)
+}
+
+// Placement is where an expression ended up in the linked output
+type Placement struct {
+	Fragment   string
+	Expression *Expression
+	Address    uint16
+	Bytes      []byte
+}
+
+// Line maps the addresses [Start, End) to the source they were compiled from. End is
+// wider than an address so that it can be $10000 for code that runs to the end of memory.
+type Line struct {
+	Start    uint16
+	End      uint32
+	Position *Position
+}
+
+// UnusedFragment is a fragment that was left out of a layout,
+// since nothing that was linked refers to it
+type UnusedFragment struct {
+	Name string
+	Span *Span
+}
+
+// Diagnostic describes the fragment as a warning, for showing with its source
+func (u UnusedFragment) Diagnostic() diagnostic.Diagnostic {
+	return diagnostic.Diagnostic{
+		Severity: diagnostic.Warning,
+		Code:     CodeUnused,
+		Message:  fmt.Sprintf("fragment '%s' is never used, so it was left out", u.Name),
+		Primary:  spanLabel(u.Span),
+	}
+}
+
+// Layout is the result of linking: the code, where it goes,
+// and where everything in it came from
+type Layout struct {
+	Origin     uint16
+	Code       []byte
+	Symbols    SymbolTable
+	Placements []Placement
+	Lines      LineTable
+	// the fragments that were left out, in name order
+	Unused []UnusedFragment
+}
 
-	WriteUint16(&b, 0x0801) // memory location to load into
-	WriteUint16(&b, 0x080C) // pointer to line of basic code
-	WriteUint16(&b, 0x000A) // line number
-	WriteUint8(&b, 0x9E)    // sys token
-	WriteUint8(&b, 0x32)    // "2"
-	WriteUint8(&b, 0x30)    // "0"
-	WriteUint8(&b, 0x36)    // "6"
-	WriteUint8(&b, 0x31)    // "1"
-	WriteUint8(&b, 0x00)    // nul, line terminator
-	WriteUint16(&b, 0x0000) // pointer to line of basic code (0x0000 == end of program)
-
-	// we start execution at address 0x0810
-	for _, expr := range main.Expressions {
-		switch t := expr.Inner.(type) {
-		case *Expression_Literal_:
-			b.Write(t.Literal.Value)
-		case *Expression_Symbol_:
-			panic("not implemented")
-		case *Expression_Unary_:
-			panic("not implemented")
-		case *Expression_Subsymbol_:
-			panic("not implemented")
+// the symbol an expression refers to, if any
+func referencedSymbol(expr *Expression) (string, bool) {
+	switch t := expr.Inner.(type) {
+	case *Expression_Symbol_:
+		return t.Symbol.Name, true
+	case *Expression_ZeroPageChoice_:
+		return t.ZeroPageChoice.Name, true
+	default:
+		return "", false
+	}
+}
+
+// the keys of the fragments every symbol is in
+func owners(o *Object) map[string]string {
+	ret := map[string]string{}
+	for key, frag := range o.Fragments {
+		ret[frag.Symbol] = key
+		for _, expr := range frag.Expressions {
+			if sub, ok := expr.Inner.(*Expression_Subsymbol_); ok {
+				ret[sub.Subsymbol.Name] = key
+			}
+		}
+	}
+	return ret
+}
+
+// the fragments reachable from the roots, with the roots first and the
+// rest in name order so that output is stable between runs
+func reachable(o *Object, roots []string) ([]string, LinkErrors) {
+	owners := owners(o)
+
+	var errs LinkErrors
+	seen := map[string]bool{}
+	queue := []string{}
+	for _, root := range roots {
+		seen[root] = true
+		queue = append(queue, root)
+	}
+	for i := 0; i < len(queue); i++ {
+		for _, expr := range o.Fragments[queue[i]].Expressions {
+			name, ok := referencedSymbol(expr)
+			if !ok {
+				continue
+			}
+			owner, ok := owners[name]
+			if !ok {
+				errs = append(errs, &LinkError{fmt.Sprintf("unresolved symbol '%s'", name), expr.Span})
+				continue
+			}
+			if !seen[owner] {
+				seen[owner] = true
+				queue = append(queue, owner)
+			}
+		}
+	}
+
+	sort.Strings(queue[len(roots):])
+	return queue, errs
+}
+
+// the fragments that aren't in linked
+func unused(o *Object, linked []string) []UnusedFragment {
+	in := map[string]bool{}
+	for _, key := range linked {
+		in[key] = true
+	}
+	var ret []UnusedFragment
+	for key, frag := range o.Fragments {
+		if !in[key] {
+			ret = append(ret, UnusedFragment{Name: key, Span: frag.Span})
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Name < ret[j].Name })
+	return ret
+}
+
+// zeroPage holds the zero page choices that have been made;
+// the ones not in it take up as much space as absolute addressing
+func expressionSize(expr *Expression, zeroPage map[*Expression]bool) (int, *LinkError) {
+	switch t := expr.Inner.(type) {
+	case *Expression_Literal_:
+		return len(t.Literal.Value), nil
+	case *Expression_Symbol_:
+		switch t.Symbol.Size {
+		case SymbolSize_WORD:
+			return 2, nil
+		case SymbolSize_BYTE, SymbolSize_RELATIVE:
+			return 1, nil
+		case SymbolSize_LONG:
+			return 3, nil
+		case SymbolSize_RELATIVE_LONG:
+			return 2, nil
+		default:
+			panic("unhandled case")
+		}
+	case *Expression_Subsymbol_:
+		return 0, nil
+	case *Expression_ZeroPageChoice_:
+		if zeroPage[expr] {
+			return 2, nil
+		}
+		return 3, nil
+	case *Expression_Unary_:
+		return 0, &LinkError{"unary expressions are not supported", expr.Span}
+	default:
+		panic("unhandled case")
+	}
+}
+
+func resolve(expr *Expression, address uint16, symbols SymbolTable, zeroPage map[*Expression]bool) ([]byte, *LinkError) {
+	switch t := expr.Inner.(type) {
+	case *Expression_Literal_:
+		return t.Literal.Value, nil
+	case *Expression_Subsymbol_:
+		return nil, nil
+	case *Expression_ZeroPageChoice_:
+		target, ok := symbols[t.ZeroPageChoice.Name]
+		if !ok {
+			return nil, &LinkError{fmt.Sprintf("unresolved symbol '%s'", t.ZeroPageChoice.Name), expr.Span}
+		}
+		if zeroPage[expr] {
+			return []byte{byte(t.ZeroPageChoice.ZeroPage), byte(target)}, nil
+		}
+		return []byte{byte(t.ZeroPageChoice.Absolute), byte(target), byte(target >> 8)}, nil
+	case *Expression_Symbol_:
+		target, ok := symbols[t.Symbol.Name]
+		if !ok {
+			return nil, &LinkError{fmt.Sprintf("unresolved symbol '%s'", t.Symbol.Name), expr.Span}
+		}
+		switch t.Symbol.Size {
+		case SymbolSize_WORD:
+			return []byte{byte(target), byte(target >> 8)}, nil
+		case SymbolSize_BYTE:
+			if target > 0xFF {
+				return nil, &LinkError{fmt.Sprintf("symbol '%s' is at $%04X, which does not fit in a byte", t.Symbol.Name, target), expr.Span}
+			}
+			return []byte{byte(target)}, nil
+		case SymbolSize_RELATIVE:
+			// relative to the address of the next instruction
+			offset := int(target) - (int(address) + 1)
+			if offset < -128 || offset > 127 {
+				return nil, &LinkError{fmt.Sprintf("branch to '%s' is out of range (%d bytes away, must be within -128 to 127)", t.Symbol.Name, offset), expr.Span}
+			}
+			return []byte{byte(int8(offset))}, nil
+		case SymbolSize_LONG:
+			// everything is linked into bank 0
+			return []byte{byte(target), byte(target >> 8), 0}, nil
+		case SymbolSize_RELATIVE_LONG:
+			// long branches wrap around within the bank, so any target can be reached
+			offset := target - (address + 2)
+			return []byte{byte(offset), byte(offset >> 8)}, nil
 		default:
 			panic("unhandled case")
 		}
+	default:
+		panic("unhandled case")
+	}
+}
+
+// the zero page addresses the Commander X16 leaves free for programs, [start, end)
+const (
+	ZeroPageStart = 0x22
+	ZeroPageEnd   = 0x80
+)
+
+// places the code fragments in order from origin, returning where every symbol in them is
+func placeCode(o *Object, order []string, origin uint16, zeroPage map[*Expression]bool) (SymbolTable, LinkErrors) {
+	var errs LinkErrors
+	symbols := SymbolTable{}
+	address := int(origin)
+	for _, key := range order {
+		frag := o.Fragments[key]
+		symbols[frag.Symbol] = uint16(address)
+		for _, expr := range frag.Expressions {
+			if sub, ok := expr.Inner.(*Expression_Subsymbol_); ok {
+				symbols[sub.Subsymbol.Name] = uint16(address)
+			}
+			size, err := expressionSize(expr, zeroPage)
+			if err != nil {
+				errs = append(errs, err)
+			}
+			address += size
+		}
+		if address > 0x10000 {
+			errs = append(errs, &LinkError{fmt.Sprintf("fragment '%s' does not fit in memory", key), frag.Span})
+			return symbols, errs
+		}
+	}
+	return symbols, errs
+}
+
+// Link lays out main and every fragment it refers to starting
+// at origin, and resolves all symbols
+func Link(o []*Object, origin uint16) (*Layout, error) {
+	bigly := Concatenate(o)
+
+	if main, ok := bigly.Fragments["main"]; !ok || main.Segment != Segment_CODE {
+		return nil, LinkErrors{{"youre missing a main fragment", nil}}
+	}
+
+	return link(bigly, origin, []string{"main"})
+}
+
+// LinkTests lays out everything the tests in the objects refer to, after
+// main if there is one, so that they can be run
+func LinkTests(o []*Object, origin uint16) (*Layout, error) {
+	bigly := Concatenate(o)
+	owners := owners(bigly)
+
+	var errs LinkErrors
+	var roots []string
+	seen := map[string]bool{}
+	if main, ok := bigly.Fragments["main"]; ok && main.Segment == Segment_CODE {
+		roots = append(roots, "main")
+		seen["main"] = true
+	}
+	var tested []string
+	for _, test := range bigly.Tests {
+		for _, step := range test.Steps {
+			for _, expr := range []*Expression{step.Address, step.Value} {
+				if expr == nil {
+					continue
+				}
+				name, ok := referencedSymbol(expr)
+				if !ok {
+					continue
+				}
+				owner, ok := owners[name]
+				if !ok {
+					errs = append(errs, &LinkError{fmt.Sprintf("unresolved symbol '%s'", name), expr.Span})
+					continue
+				}
+				if !seen[owner] {
+					seen[owner] = true
+					tested = append(tested, owner)
+				}
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	sort.Strings(tested)
+	roots = append(roots, tested...)
+	if len(roots) == 0 {
+		return nil, LinkErrors{{"there is nothing to test", nil}}
+	}
+
+	return link(bigly, origin, roots)
+}
+
+// Evaluate resolves an expression that isn't part of the code, like a test's, to a number
+func (l *Layout) Evaluate(expr *Expression) (uint32, error) {
+	b, err := resolve(expr, 0, l.Symbols, nil)
+	if err != nil {
+		return 0, err
+	}
+	var value uint32
+	for i, v := range b {
+		value |= uint32(v) << (8 * i)
+	}
+	return value, nil
+}
+
+func link(bigly *Object, origin uint16, roots []string) (*Layout, error) {
+	order, errs := reachable(bigly, roots)
+
+	// zero page fragments are placed first, since their
+	// addresses decide how big the code referring to them is
+	zeroPageSymbols := SymbolTable{}
+	var code []string
+	zeroPageAddress := ZeroPageStart
+	for _, key := range order {
+		frag := bigly.Fragments[key]
+		if frag.Segment != Segment_ZERO_PAGE {
+			code = append(code, key)
+			continue
+		}
+		zeroPageSymbols[frag.Symbol] = uint16(zeroPageAddress)
+		zeroPageAddress += int(frag.Reserve)
+		if zeroPageAddress > ZeroPageEnd {
+			errs = append(errs, &LinkError{fmt.Sprintf("fragment '%s' does not fit in zero page", key), frag.Span})
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	// first pass: figure out where everything goes. Every choice starts out
+	// absolute, and switches to zero page once its symbol is known to be there.
+	// That only ever makes code smaller, moving symbols down, so this settles
+	zeroPage := map[*Expression]bool{}
+	var symbols SymbolTable
+	for {
+		symbols, errs = placeCode(bigly, code, origin, zeroPage)
+		if len(errs) > 0 {
+			return nil, errs
+		}
+		for name, address := range zeroPageSymbols {
+			symbols[name] = address
+		}
+
+		changed := false
+		for _, key := range code {
+			for _, expr := range bigly.Fragments[key].Expressions {
+				choice, ok := expr.Inner.(*Expression_ZeroPageChoice_)
+				if !ok || zeroPage[expr] {
+					continue
+				}
+				if address, ok := symbols[choice.ZeroPageChoice.Name]; ok && address <= 0xFF {
+					zeroPage[expr] = true
+					changed = true
+				}
+			}
+		}
+		if !changed {
+			break
+		}
 	}
+	layout := &Layout{Origin: origin, Symbols: symbols, Unused: unused(bigly, order)}
+
+	// second pass: fill in the bytes
+	var out bytes.Buffer
+	for _, key := range code {
+		for _, expr := range bigly.Fragments[key].Expressions {
+			at := origin + uint16(out.Len())
+			b, err := resolve(expr, at, layout.Symbols, zeroPage)
+			if err != nil {
+				errs = append(errs, err)
+				size, _ := expressionSize(expr, zeroPage)
+				b = make([]byte, size)
+			}
+			out.Write(b)
+			layout.Placements = append(layout.Placements, Placement{
+				Fragment:   key,
+				Expression: expr,
+				Address:    at,
+				Bytes:      b,
+			})
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	layout.Code = out.Bytes()
+	layout.Lines = lines(layout.Placements)
+	return layout, nil
+}
+
+// collapses placements into the ranges of addresses produced by each source line
+func lines(placements []Placement) LineTable {
+	var ret LineTable
+	for _, p := range placements {
+		if len(p.Bytes) == 0 || p.Expression.Span == nil || p.Expression.Span.Start == nil {
+			continue
+		}
+		end := uint32(p.Address) + uint32(len(p.Bytes))
+		if n := len(ret); n > 0 {
+			last := &ret[n-1]
+			if last.End == uint32(p.Address) && last.Position.Filename == p.Expression.Span.Start.Filename && last.Position.Line == p.Expression.Span.Start.Line {
+				last.End = end
+				continue
+			}
+		}
+		ret = append(ret, Line{Start: p.Address, End: end, Position: p.Expression.Span.Start})
+	}
+	return ret
+}
+
+// the address programs are loaded into
+const PrgLoadAddress = 0x0801
+
+// the address code starts at, right after the BASIC stub
+const PrgCodeAddress = 0x080D
 
-	return b.Bytes(), nil
+// returns a prg file
+func LinkToPrg(o []*Object) ([]byte, *Layout, error) {
+	layout, err := Link(o, PrgCodeAddress)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var b bytes.Buffer
+
+	WriteUint16(&b, PrgLoadAddress) // memory location to load into
+	WriteUint16(&b, 0x080C)         // pointer to line of basic code
+	WriteUint16(&b, 0x000A)         // line number
+	WriteUint8(&b, 0x9E)            // sys token
+	WriteUint8(&b, 0x32)            // "2"
+	WriteUint8(&b, 0x30)            // "0"
+	WriteUint8(&b, 0x36)            // "6"
+	WriteUint8(&b, 0x31)            // "1"
+	WriteUint8(&b, 0x00)            // nul, line terminator
+	WriteUint16(&b, 0x0000)         // pointer to line of basic code (0x0000 == end of program)
+
+	// we start execution at address 0x080D, which is 2061 in decimal
+	b.Write(layout.Code)
+
+	return b.Bytes(), layout, nil
+}
+
+// SplitPrg separates a prg file into the address it's loaded at and its contents
+func SplitPrg(prg []byte) (uint16, []byte, error) {
+	if len(prg) < 2 {
+		return 0, nil, errors.New("prg file is too short to have a load address")
+	}
+	return binary.LittleEndian.Uint16(prg), prg[2:], nil
+}
+
+// SkipBasicStub recognises a single line BASIC program calling SYS at the start
+// of code loaded at the given address, like the one LinkToPrg writes. It returns
+// the address SYS jumps to, and the address and contents of what follows the stub.
+func SkipBasicStub(load uint16, code []byte) (sys uint16, start uint16, rest []byte, ok bool) {
+	if len(code) < 5 || code[4] != 0x9E {
+		return 0, 0, nil, false
+	}
+	// the pointer to the next line isn't checked since the KERNAL fixes it up
+	// on load anyway, so look for the end of the line and the end of the program
+	end := bytes.IndexByte(code[5:], 0x00) + 5
+	if end < 5 || end+3 > len(code) || code[end+1] != 0x00 || code[end+2] != 0x00 {
+		return 0, 0, nil, false
+	}
+
+	digits := strings.TrimSpace(string(code[5:end]))
+	address, err := strconv.ParseUint(digits, 10, 16)
+	if err != nil {
+		return 0, 0, nil, false
+	}
+	return uint16(address), load + uint16(end+3), code[end+3:], true
 }
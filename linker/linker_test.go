@@ -0,0 +1,120 @@
+package linker
+
+import (
+	"Sano/diagnostic"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func testSpan(line, column, endColumn int32) *Span {
+	return &Span{
+		Start: &Position{Filename: "test.san", Line: line, Column: column},
+		End:   &Position{Filename: "test.san", Line: line, Column: endColumn},
+	}
+}
+
+func literal(span *Span, b ...byte) *Expression {
+	return &Expression{Inner: &Expression_Literal_{Literal: &Expression_Literal{Value: b}}, Span: span}
+}
+
+func symbol(span *Span, name string, size SymbolSize) *Expression {
+	return &Expression{Inner: &Expression_Symbol_{Symbol: &Expression_Symbol{Name: name, Size: size}}, Span: span}
+}
+
+func subsymbol(span *Span, name string) *Expression {
+	return &Expression{Inner: &Expression_Subsymbol_{Subsymbol: &Expression_Subsymbol{Name: name}}, Span: span}
+}
+
+func fragment(span *Span, name string, exprs ...*Expression) *Fragment {
+	return &Fragment{Symbol: name, Span: span, Segment: Segment_CODE, Expressions: exprs}
+}
+
+// links o, expecting it to fail, and returns the diagnostics for what went wrong
+func linkErrors(t *testing.T, o *Object) []diagnostic.Diagnostic {
+	t.Helper()
+	_, err := Link([]*Object{o}, 0x1000)
+	errs, ok := err.(LinkErrors)
+	if !ok {
+		t.Fatalf("expected LinkErrors, but got %v", err)
+	}
+	return errs.Diagnostics()
+}
+
+func TestUnresolvedSymbolsPointAtTheirOperand(t *testing.T) {
+	diagnostics := linkErrors(t, &Object{Fragments: map[string]*Fragment{
+		"main": fragment(testSpan(1, 1, 20), "main",
+			literal(testSpan(1, 9, 18), 0x20),
+			symbol(testSpan(1, 14, 18), "nope", SymbolSize_WORD),
+		),
+	}})
+	expected := []diagnostic.Diagnostic{{
+		Severity: diagnostic.Error,
+		Code:     CodeLink,
+		Message:  "unresolved symbol 'nope'",
+		Primary:  diagnostic.Label{Filename: "test.san", Line: 1, Column: 14, EndLine: 1, EndColumn: 18},
+	}}
+	if !reflect.DeepEqual(diagnostics, expected) {
+		t.Fatalf("got %+v, expected %+v", diagnostics, expected)
+	}
+}
+
+func TestBranchesOutOfRangePointAtTheirOperand(t *testing.T) {
+	diagnostics := linkErrors(t, &Object{Fragments: map[string]*Fragment{
+		"main": fragment(testSpan(1, 1, 2), "main",
+			literal(testSpan(2, 2, 11), 0xD0),
+			symbol(testSpan(2, 7, 11), "main/far", SymbolSize_RELATIVE),
+			literal(testSpan(3, 2, 10), make([]byte, 200)...),
+			subsymbol(testSpan(4, 2, 7), "main/far"),
+			literal(testSpan(5, 2, 8), 0x60),
+		),
+	}})
+	if len(diagnostics) != 1 {
+		t.Fatalf("expected one error, but got %+v", diagnostics)
+	}
+	d := diagnostics[0]
+	if d.Code != CodeLink || !strings.HasPrefix(d.Message, "branch to 'main/far' is out of range (200 bytes away") {
+		t.Errorf("got %s %q", d.Code, d.Message)
+	}
+	expected := diagnostic.Label{Filename: "test.san", Line: 2, Column: 7, EndLine: 2, EndColumn: 11}
+	if d.Primary != expected {
+		t.Errorf("got %+v, expected %+v", d.Primary, expected)
+	}
+}
+
+func TestErrorsWithoutASpan(t *testing.T) {
+	diagnostics := linkErrors(t, &Object{Fragments: map[string]*Fragment{}})
+	expected := []diagnostic.Diagnostic{{Severity: diagnostic.Error, Code: CodeLink, Message: "youre missing a main fragment"}}
+	if !reflect.DeepEqual(diagnostics, expected) {
+		t.Fatalf("got %+v, expected %+v", diagnostics, expected)
+	}
+}
+
+func TestUnusedFragmentsAreReported(t *testing.T) {
+	layout, err := Link([]*Object{{Fragments: map[string]*Fragment{
+		"main":   fragment(testSpan(1, 1, 20), "main", literal(nil, 0x20), symbol(nil, "used", SymbolSize_WORD)),
+		"used":   fragment(testSpan(2, 1, 16), "used", literal(nil, 0x60)),
+		"unused": fragment(testSpan(3, 1, 18), "unused", literal(nil, 0x60)),
+		"zp":     {Symbol: "zp", Span: testSpan(4, 1, 16), Segment: Segment_ZERO_PAGE, Reserve: 1},
+	}}}, 0x1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []UnusedFragment{{Name: "unused", Span: testSpan(3, 1, 18)}, {Name: "zp", Span: testSpan(4, 1, 16)}}
+	if len(layout.Unused) != len(expected) {
+		t.Fatalf("got %v, expected %v", layout.Unused, expected)
+	}
+	for i, u := range layout.Unused {
+		if u.Name != expected[i].Name || u.Span.Start.Line != expected[i].Span.Start.Line {
+			t.Errorf("got %v, expected %v", u, expected[i])
+		}
+	}
+
+	d := layout.Unused[0].Diagnostic()
+	if d.Severity != diagnostic.Warning || d.Code != CodeUnused || d.Message != "fragment 'unused' is never used, so it was left out" {
+		t.Errorf("got %+v", d)
+	}
+	if d.Primary != (diagnostic.Label{Filename: "test.san", Line: 3, Column: 1, EndLine: 3, EndColumn: 18}) {
+		t.Errorf("got %+v", d.Primary)
+	}
+}
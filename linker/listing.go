@@ -0,0 +1,101 @@
+package linker
+
+import (
+	"Sano/cpu"
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// Source is the text of a file that was compiled
+type Source struct {
+	Filename string
+	Text     []byte
+}
+
+type sourceLine struct {
+	filename string
+	line     int32
+}
+
+// how many bytes are listed next to each line, with the rest on lines of their own
+const listingBytesPerLine = 4
+
+// WriteListing writes every line of the sources next to the addresses and bytes they ended up
+// as in the layout, followed by the symbol table. If set isn't nil, instructions are annotated
+// with the cycles they take on it.
+func WriteListing(w io.Writer, layout *Layout, sources []Source, set cpu.OpcodeSet) error {
+	ranges := map[sourceLine][]Line{}
+	for _, l := range layout.Lines {
+		key := sourceLine{l.Position.Filename, l.Position.Line}
+		ranges[key] = append(ranges[key], l)
+	}
+
+	instructions := map[uint16]cpu.Instruction{}
+	if set != nil {
+		for _, s := range Summarize(layout, set) {
+			for _, inst := range decode(layout, set, s.Fragment) {
+				instructions[inst.Address] = inst
+			}
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	row := func(address string, b []byte, cycles string, line string, text []byte) {
+		if set != nil {
+			fmt.Fprintf(bw, "%-4s  %-*s  %-5s  %5s  %s\n", address, listingBytesPerLine*3-1, formatBytes(b), cycles, line, text)
+		} else {
+			fmt.Fprintf(bw, "%-4s  %-*s  %5s  %s\n", address, listingBytesPerLine*3-1, formatBytes(b), line, text)
+		}
+	}
+
+	for i, source := range sources {
+		if i > 0 {
+			fmt.Fprintln(bw)
+		}
+		fmt.Fprintf(bw, "%s\n\n", source.Filename)
+
+		text := bytes.TrimSuffix(source.Text, []byte("\n"))
+		for n, line := range bytes.Split(text, []byte("\n")) {
+			line = bytes.TrimSuffix(line, []byte("\r"))
+			number := fmt.Sprint(n + 1)
+			listed := false
+			for _, r := range ranges[sourceLine{source.Filename, int32(n + 1)}] {
+				code := layout.Code[int(r.Start)-int(layout.Origin) : int(r.End)-int(layout.Origin)]
+				for offset := 0; offset < len(code); offset += listingBytesPerLine {
+					address := r.Start + uint16(offset)
+					end := offset + listingBytesPerLine
+					if end > len(code) {
+						end = len(code)
+					}
+					cycles := ""
+					if inst, ok := instructions[address]; ok {
+						cycles = inst.CycleRange()
+					}
+					if listed {
+						row(fmt.Sprintf("%04X", address), code[offset:end], cycles, "", nil)
+					} else {
+						row(fmt.Sprintf("%04X", address), code[offset:end], cycles, number, line)
+						listed = true
+					}
+				}
+			}
+			if !listed {
+				row("", nil, "", number, line)
+			}
+		}
+	}
+
+	fmt.Fprintf(bw, "\nsymbols\n\n")
+	names := make([]string, 0, len(layout.Symbols))
+	for name := range layout.Symbols {
+		names = append(names, name)
+	}
+	sortSymbols(names, layout.Symbols)
+	for _, name := range names {
+		fmt.Fprintf(bw, "%04X  %s\n", layout.Symbols[name], name)
+	}
+
+	return bw.Flush()
+}
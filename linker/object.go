@@ -0,0 +1,30 @@
+package linker
+
+import (
+	"fmt"
+	"io"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func ReadObject(r io.Reader) (*Object, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object: %w", err)
+	}
+	o := &Object{}
+	err = proto.Unmarshal(data, o)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode object: %w", err)
+	}
+	return o, nil
+}
+
+func WriteObject(w io.Writer, o *Object) error {
+	data, err := proto.Marshal(o)
+	if err != nil {
+		return fmt.Errorf("failed to encode object: %w", err)
+	}
+	_, err = w.Write(data)
+	return err
+}
@@ -20,6 +20,52 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type Segment int32
+
+const (
+	Segment_CODE      Segment = 0
+	Segment_ZERO_PAGE Segment = 1
+)
+
+// Enum value maps for Segment.
+var (
+	Segment_name = map[int32]string{
+		0: "CODE",
+		1: "ZERO_PAGE",
+	}
+	Segment_value = map[string]int32{
+		"CODE":      0,
+		"ZERO_PAGE": 1,
+	}
+)
+
+func (x Segment) Enum() *Segment {
+	p := new(Segment)
+	*p = x
+	return p
+}
+
+func (x Segment) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Segment) Descriptor() protoreflect.EnumDescriptor {
+	return file_linker_object_proto_enumTypes[0].Descriptor()
+}
+
+func (Segment) Type() protoreflect.EnumType {
+	return &file_linker_object_proto_enumTypes[0]
+}
+
+func (x Segment) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Segment.Descriptor instead.
+func (Segment) EnumDescriptor() ([]byte, []int) {
+	return file_linker_object_proto_rawDescGZIP(), []int{0}
+}
+
 type UnaryType int32
 
 const (
@@ -50,11 +96,11 @@ func (x UnaryType) String() string {
 }
 
 func (UnaryType) Descriptor() protoreflect.EnumDescriptor {
-	return file_linker_object_proto_enumTypes[0].Descriptor()
+	return file_linker_object_proto_enumTypes[1].Descriptor()
 }
 
 func (UnaryType) Type() protoreflect.EnumType {
-	return &file_linker_object_proto_enumTypes[0]
+	return &file_linker_object_proto_enumTypes[1]
 }
 
 func (x UnaryType) Number() protoreflect.EnumNumber {
@@ -63,15 +109,17 @@ func (x UnaryType) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use UnaryType.Descriptor instead.
 func (UnaryType) EnumDescriptor() ([]byte, []int) {
-	return file_linker_object_proto_rawDescGZIP(), []int{0}
+	return file_linker_object_proto_rawDescGZIP(), []int{1}
 }
 
 type SymbolSize int32
 
 const (
-	SymbolSize_WORD     SymbolSize = 0
-	SymbolSize_BYTE     SymbolSize = 1
-	SymbolSize_RELATIVE SymbolSize = 2
+	SymbolSize_WORD          SymbolSize = 0
+	SymbolSize_BYTE          SymbolSize = 1
+	SymbolSize_RELATIVE      SymbolSize = 2
+	SymbolSize_LONG          SymbolSize = 3
+	SymbolSize_RELATIVE_LONG SymbolSize = 4
 )
 
 // Enum value maps for SymbolSize.
@@ -80,11 +128,15 @@ var (
 		0: "WORD",
 		1: "BYTE",
 		2: "RELATIVE",
+		3: "LONG",
+		4: "RELATIVE_LONG",
 	}
 	SymbolSize_value = map[string]int32{
-		"WORD":     0,
-		"BYTE":     1,
-		"RELATIVE": 2,
+		"WORD":          0,
+		"BYTE":          1,
+		"RELATIVE":      2,
+		"LONG":          3,
+		"RELATIVE_LONG": 4,
 	}
 )
 
@@ -99,11 +151,11 @@ func (x SymbolSize) String() string {
 }
 
 func (SymbolSize) Descriptor() protoreflect.EnumDescriptor {
-	return file_linker_object_proto_enumTypes[1].Descriptor()
+	return file_linker_object_proto_enumTypes[2].Descriptor()
 }
 
 func (SymbolSize) Type() protoreflect.EnumType {
-	return &file_linker_object_proto_enumTypes[1]
+	return &file_linker_object_proto_enumTypes[2]
 }
 
 func (x SymbolSize) Number() protoreflect.EnumNumber {
@@ -112,7 +164,56 @@ func (x SymbolSize) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use SymbolSize.Descriptor instead.
 func (SymbolSize) EnumDescriptor() ([]byte, []int) {
-	return file_linker_object_proto_rawDescGZIP(), []int{1}
+	return file_linker_object_proto_rawDescGZIP(), []int{2}
+}
+
+type TestStep_Kind int32
+
+const (
+	TestStep_SET    TestStep_Kind = 0
+	TestStep_CALL   TestStep_Kind = 1
+	TestStep_EXPECT TestStep_Kind = 2
+)
+
+// Enum value maps for TestStep_Kind.
+var (
+	TestStep_Kind_name = map[int32]string{
+		0: "SET",
+		1: "CALL",
+		2: "EXPECT",
+	}
+	TestStep_Kind_value = map[string]int32{
+		"SET":    0,
+		"CALL":   1,
+		"EXPECT": 2,
+	}
+)
+
+func (x TestStep_Kind) Enum() *TestStep_Kind {
+	p := new(TestStep_Kind)
+	*p = x
+	return p
+}
+
+func (x TestStep_Kind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TestStep_Kind) Descriptor() protoreflect.EnumDescriptor {
+	return file_linker_object_proto_enumTypes[3].Descriptor()
+}
+
+func (TestStep_Kind) Type() protoreflect.EnumType {
+	return &file_linker_object_proto_enumTypes[3]
+}
+
+func (x TestStep_Kind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TestStep_Kind.Descriptor instead.
+func (TestStep_Kind) EnumDescriptor() ([]byte, []int) {
+	return file_linker_object_proto_rawDescGZIP(), []int{2, 0}
 }
 
 type Object struct {
@@ -121,6 +222,7 @@ type Object struct {
 	unknownFields protoimpl.UnknownFields
 
 	Fragments map[string]*Fragment `protobuf:"bytes,1,rep,name=fragments,proto3" json:"fragments,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Tests     []*Test              `protobuf:"bytes,2,rep,name=tests,proto3" json:"tests,omitempty"`
 }
 
 func (x *Object) Reset() {
@@ -162,18 +264,179 @@ func (x *Object) GetFragments() map[string]*Fragment {
 	return nil
 }
 
+func (x *Object) GetTests() []*Test {
+	if x != nil {
+		return x.Tests
+	}
+	return nil
+}
+
+type Test struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name  string      `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Span  *Span       `protobuf:"bytes,2,opt,name=span,proto3" json:"span,omitempty"`
+	Steps []*TestStep `protobuf:"bytes,3,rep,name=steps,proto3" json:"steps,omitempty"`
+}
+
+func (x *Test) Reset() {
+	*x = Test{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_linker_object_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Test) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Test) ProtoMessage() {}
+
+func (x *Test) ProtoReflect() protoreflect.Message {
+	mi := &file_linker_object_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Test.ProtoReflect.Descriptor instead.
+func (*Test) Descriptor() ([]byte, []int) {
+	return file_linker_object_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Test) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Test) GetSpan() *Span {
+	if x != nil {
+		return x.Span
+	}
+	return nil
+}
+
+func (x *Test) GetSteps() []*TestStep {
+	if x != nil {
+		return x.Steps
+	}
+	return nil
+}
+
+type TestStep struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Kind     TestStep_Kind `protobuf:"varint,1,opt,name=kind,proto3,enum=TestStep_Kind" json:"kind,omitempty"`
+	Register string        `protobuf:"bytes,2,opt,name=register,proto3" json:"register,omitempty"`
+	Address  *Expression   `protobuf:"bytes,3,opt,name=address,proto3" json:"address,omitempty"`
+	Value    *Expression   `protobuf:"bytes,4,opt,name=value,proto3" json:"value,omitempty"`
+	Span     *Span         `protobuf:"bytes,5,opt,name=span,proto3" json:"span,omitempty"`
+	Vram     bool          `protobuf:"varint,6,opt,name=vram,proto3" json:"vram,omitempty"`
+}
+
+func (x *TestStep) Reset() {
+	*x = TestStep{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_linker_object_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TestStep) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TestStep) ProtoMessage() {}
+
+func (x *TestStep) ProtoReflect() protoreflect.Message {
+	mi := &file_linker_object_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TestStep.ProtoReflect.Descriptor instead.
+func (*TestStep) Descriptor() ([]byte, []int) {
+	return file_linker_object_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *TestStep) GetKind() TestStep_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return TestStep_SET
+}
+
+func (x *TestStep) GetRegister() string {
+	if x != nil {
+		return x.Register
+	}
+	return ""
+}
+
+func (x *TestStep) GetAddress() *Expression {
+	if x != nil {
+		return x.Address
+	}
+	return nil
+}
+
+func (x *TestStep) GetValue() *Expression {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *TestStep) GetSpan() *Span {
+	if x != nil {
+		return x.Span
+	}
+	return nil
+}
+
+func (x *TestStep) GetVram() bool {
+	if x != nil {
+		return x.Vram
+	}
+	return false
+}
+
 type Fragment struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
 	Expressions []*Expression `protobuf:"bytes,1,rep,name=expressions,proto3" json:"expressions,omitempty"`
+	Symbol      string        `protobuf:"bytes,2,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Span        *Span         `protobuf:"bytes,3,opt,name=span,proto3" json:"span,omitempty"`
+	Segment     Segment       `protobuf:"varint,4,opt,name=segment,proto3,enum=Segment" json:"segment,omitempty"`
+	Reserve     uint32        `protobuf:"varint,5,opt,name=reserve,proto3" json:"reserve,omitempty"`
 }
 
 func (x *Fragment) Reset() {
 	*x = Fragment{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_linker_object_proto_msgTypes[1]
+		mi := &file_linker_object_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -186,7 +449,7 @@ func (x *Fragment) String() string {
 func (*Fragment) ProtoMessage() {}
 
 func (x *Fragment) ProtoReflect() protoreflect.Message {
-	mi := &file_linker_object_proto_msgTypes[1]
+	mi := &file_linker_object_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -199,7 +462,7 @@ func (x *Fragment) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Fragment.ProtoReflect.Descriptor instead.
 func (*Fragment) Descriptor() ([]byte, []int) {
-	return file_linker_object_proto_rawDescGZIP(), []int{1}
+	return file_linker_object_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *Fragment) GetExpressions() []*Expression {
@@ -209,24 +472,180 @@ func (x *Fragment) GetExpressions() []*Expression {
 	return nil
 }
 
+func (x *Fragment) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *Fragment) GetSpan() *Span {
+	if x != nil {
+		return x.Span
+	}
+	return nil
+}
+
+func (x *Fragment) GetSegment() Segment {
+	if x != nil {
+		return x.Segment
+	}
+	return Segment_CODE
+}
+
+func (x *Fragment) GetReserve() uint32 {
+	if x != nil {
+		return x.Reserve
+	}
+	return 0
+}
+
+type Position struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Filename string `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	Offset   int32  `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	Line     int32  `protobuf:"varint,3,opt,name=line,proto3" json:"line,omitempty"`
+	Column   int32  `protobuf:"varint,4,opt,name=column,proto3" json:"column,omitempty"`
+}
+
+func (x *Position) Reset() {
+	*x = Position{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_linker_object_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Position) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Position) ProtoMessage() {}
+
+func (x *Position) ProtoReflect() protoreflect.Message {
+	mi := &file_linker_object_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Position.ProtoReflect.Descriptor instead.
+func (*Position) Descriptor() ([]byte, []int) {
+	return file_linker_object_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Position) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *Position) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *Position) GetLine() int32 {
+	if x != nil {
+		return x.Line
+	}
+	return 0
+}
+
+func (x *Position) GetColumn() int32 {
+	if x != nil {
+		return x.Column
+	}
+	return 0
+}
+
+type Span struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Start *Position `protobuf:"bytes,1,opt,name=start,proto3" json:"start,omitempty"`
+	End   *Position `protobuf:"bytes,2,opt,name=end,proto3" json:"end,omitempty"`
+}
+
+func (x *Span) Reset() {
+	*x = Span{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_linker_object_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Span) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Span) ProtoMessage() {}
+
+func (x *Span) ProtoReflect() protoreflect.Message {
+	mi := &file_linker_object_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Span.ProtoReflect.Descriptor instead.
+func (*Span) Descriptor() ([]byte, []int) {
+	return file_linker_object_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Span) GetStart() *Position {
+	if x != nil {
+		return x.Start
+	}
+	return nil
+}
+
+func (x *Span) GetEnd() *Position {
+	if x != nil {
+		return x.End
+	}
+	return nil
+}
+
 type Expression struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
 	// Types that are assignable to Inner:
-	//
 	//	*Expression_Literal_
 	//	*Expression_Symbol_
 	//	*Expression_Unary_
 	//	*Expression_Subsymbol_
+	//	*Expression_ZeroPageChoice_
 	Inner isExpression_Inner `protobuf_oneof:"inner"`
+	Span  *Span              `protobuf:"bytes,5,opt,name=span,proto3" json:"span,omitempty"`
+	Data  bool               `protobuf:"varint,7,opt,name=data,proto3" json:"data,omitempty"`
 }
 
 func (x *Expression) Reset() {
 	*x = Expression{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_linker_object_proto_msgTypes[2]
+		mi := &file_linker_object_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -239,7 +658,7 @@ func (x *Expression) String() string {
 func (*Expression) ProtoMessage() {}
 
 func (x *Expression) ProtoReflect() protoreflect.Message {
-	mi := &file_linker_object_proto_msgTypes[2]
+	mi := &file_linker_object_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -252,7 +671,7 @@ func (x *Expression) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Expression.ProtoReflect.Descriptor instead.
 func (*Expression) Descriptor() ([]byte, []int) {
-	return file_linker_object_proto_rawDescGZIP(), []int{2}
+	return file_linker_object_proto_rawDescGZIP(), []int{6}
 }
 
 func (m *Expression) GetInner() isExpression_Inner {
@@ -290,6 +709,27 @@ func (x *Expression) GetSubsymbol() *Expression_Subsymbol {
 	return nil
 }
 
+func (x *Expression) GetZeroPageChoice() *Expression_ZeroPageChoice {
+	if x, ok := x.GetInner().(*Expression_ZeroPageChoice_); ok {
+		return x.ZeroPageChoice
+	}
+	return nil
+}
+
+func (x *Expression) GetSpan() *Span {
+	if x != nil {
+		return x.Span
+	}
+	return nil
+}
+
+func (x *Expression) GetData() bool {
+	if x != nil {
+		return x.Data
+	}
+	return false
+}
+
 type isExpression_Inner interface {
 	isExpression_Inner()
 }
@@ -310,6 +750,10 @@ type Expression_Subsymbol_ struct {
 	Subsymbol *Expression_Subsymbol `protobuf:"bytes,4,opt,name=subsymbol,proto3,oneof"`
 }
 
+type Expression_ZeroPageChoice_ struct {
+	ZeroPageChoice *Expression_ZeroPageChoice `protobuf:"bytes,6,opt,name=zero_page_choice,json=zeroPageChoice,proto3,oneof"`
+}
+
 func (*Expression_Literal_) isExpression_Inner() {}
 
 func (*Expression_Symbol_) isExpression_Inner() {}
@@ -318,6 +762,8 @@ func (*Expression_Unary_) isExpression_Inner() {}
 
 func (*Expression_Subsymbol_) isExpression_Inner() {}
 
+func (*Expression_ZeroPageChoice_) isExpression_Inner() {}
+
 type Expression_Literal struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -329,7 +775,7 @@ type Expression_Literal struct {
 func (x *Expression_Literal) Reset() {
 	*x = Expression_Literal{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_linker_object_proto_msgTypes[4]
+		mi := &file_linker_object_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -342,7 +788,7 @@ func (x *Expression_Literal) String() string {
 func (*Expression_Literal) ProtoMessage() {}
 
 func (x *Expression_Literal) ProtoReflect() protoreflect.Message {
-	mi := &file_linker_object_proto_msgTypes[4]
+	mi := &file_linker_object_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -355,7 +801,7 @@ func (x *Expression_Literal) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Expression_Literal.ProtoReflect.Descriptor instead.
 func (*Expression_Literal) Descriptor() ([]byte, []int) {
-	return file_linker_object_proto_rawDescGZIP(), []int{2, 0}
+	return file_linker_object_proto_rawDescGZIP(), []int{6, 0}
 }
 
 func (x *Expression_Literal) GetValue() []byte {
@@ -377,7 +823,7 @@ type Expression_Symbol struct {
 func (x *Expression_Symbol) Reset() {
 	*x = Expression_Symbol{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_linker_object_proto_msgTypes[5]
+		mi := &file_linker_object_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -390,7 +836,7 @@ func (x *Expression_Symbol) String() string {
 func (*Expression_Symbol) ProtoMessage() {}
 
 func (x *Expression_Symbol) ProtoReflect() protoreflect.Message {
-	mi := &file_linker_object_proto_msgTypes[5]
+	mi := &file_linker_object_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -403,7 +849,7 @@ func (x *Expression_Symbol) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Expression_Symbol.ProtoReflect.Descriptor instead.
 func (*Expression_Symbol) Descriptor() ([]byte, []int) {
-	return file_linker_object_proto_rawDescGZIP(), []int{2, 1}
+	return file_linker_object_proto_rawDescGZIP(), []int{6, 1}
 }
 
 func (x *Expression_Symbol) GetName() string {
@@ -431,7 +877,7 @@ type Expression_Subsymbol struct {
 func (x *Expression_Subsymbol) Reset() {
 	*x = Expression_Subsymbol{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_linker_object_proto_msgTypes[6]
+		mi := &file_linker_object_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -444,7 +890,7 @@ func (x *Expression_Subsymbol) String() string {
 func (*Expression_Subsymbol) ProtoMessage() {}
 
 func (x *Expression_Subsymbol) ProtoReflect() protoreflect.Message {
-	mi := &file_linker_object_proto_msgTypes[6]
+	mi := &file_linker_object_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -457,7 +903,7 @@ func (x *Expression_Subsymbol) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Expression_Subsymbol.ProtoReflect.Descriptor instead.
 func (*Expression_Subsymbol) Descriptor() ([]byte, []int) {
-	return file_linker_object_proto_rawDescGZIP(), []int{2, 2}
+	return file_linker_object_proto_rawDescGZIP(), []int{6, 2}
 }
 
 func (x *Expression_Subsymbol) GetName() string {
@@ -479,7 +925,7 @@ type Expression_Unary struct {
 func (x *Expression_Unary) Reset() {
 	*x = Expression_Unary{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_linker_object_proto_msgTypes[7]
+		mi := &file_linker_object_proto_msgTypes[11]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -492,7 +938,7 @@ func (x *Expression_Unary) String() string {
 func (*Expression_Unary) ProtoMessage() {}
 
 func (x *Expression_Unary) ProtoReflect() protoreflect.Message {
-	mi := &file_linker_object_proto_msgTypes[7]
+	mi := &file_linker_object_proto_msgTypes[11]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -505,7 +951,7 @@ func (x *Expression_Unary) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Expression_Unary.ProtoReflect.Descriptor instead.
 func (*Expression_Unary) Descriptor() ([]byte, []int) {
-	return file_linker_object_proto_rawDescGZIP(), []int{2, 3}
+	return file_linker_object_proto_rawDescGZIP(), []int{6, 3}
 }
 
 func (x *Expression_Unary) GetKind() UnaryType {
@@ -522,56 +968,177 @@ func (x *Expression_Unary) GetValue() *Expression {
 	return nil
 }
 
+type Expression_ZeroPageChoice struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name     string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	ZeroPage uint32 `protobuf:"varint,2,opt,name=zero_page,json=zeroPage,proto3" json:"zero_page,omitempty"`
+	Absolute uint32 `protobuf:"varint,3,opt,name=absolute,proto3" json:"absolute,omitempty"`
+}
+
+func (x *Expression_ZeroPageChoice) Reset() {
+	*x = Expression_ZeroPageChoice{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_linker_object_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Expression_ZeroPageChoice) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Expression_ZeroPageChoice) ProtoMessage() {}
+
+func (x *Expression_ZeroPageChoice) ProtoReflect() protoreflect.Message {
+	mi := &file_linker_object_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Expression_ZeroPageChoice.ProtoReflect.Descriptor instead.
+func (*Expression_ZeroPageChoice) Descriptor() ([]byte, []int) {
+	return file_linker_object_proto_rawDescGZIP(), []int{6, 4}
+}
+
+func (x *Expression_ZeroPageChoice) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Expression_ZeroPageChoice) GetZeroPage() uint32 {
+	if x != nil {
+		return x.ZeroPage
+	}
+	return 0
+}
+
+func (x *Expression_ZeroPageChoice) GetAbsolute() uint32 {
+	if x != nil {
+		return x.Absolute
+	}
+	return 0
+}
+
 var File_linker_object_proto protoreflect.FileDescriptor
 
 var file_linker_object_proto_rawDesc = []byte{
 	0x0a, 0x13, 0x6c, 0x69, 0x6e, 0x6b, 0x65, 0x72, 0x2f, 0x6f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x2e,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x87, 0x01, 0x0a, 0x06, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xa4, 0x01, 0x0a, 0x06, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74,
 	0x12, 0x34, 0x0a, 0x09, 0x66, 0x72, 0x61, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x01, 0x20,
 	0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x2e, 0x46, 0x72, 0x61,
 	0x67, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x09, 0x66, 0x72, 0x61,
-	0x67, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x1a, 0x47, 0x0a, 0x0e, 0x46, 0x72, 0x61, 0x67, 0x6d, 0x65,
-	0x6e, 0x74, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x1f, 0x0a, 0x05, 0x76, 0x61,
-	0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x09, 0x2e, 0x46, 0x72, 0x61, 0x67,
-	0x6d, 0x65, 0x6e, 0x74, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22,
-	0x39, 0x0a, 0x08, 0x46, 0x72, 0x61, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x2d, 0x0a, 0x0b, 0x65,
-	0x78, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
-	0x32, 0x0b, 0x2e, 0x45, 0x78, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x0b, 0x65,
-	0x78, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0xa3, 0x03, 0x0a, 0x0a, 0x45,
-	0x78, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x2f, 0x0a, 0x07, 0x6c, 0x69, 0x74,
-	0x65, 0x72, 0x61, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x45, 0x78, 0x70,
-	0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x2e, 0x4c, 0x69, 0x74, 0x65, 0x72, 0x61, 0x6c, 0x48,
-	0x00, 0x52, 0x07, 0x6c, 0x69, 0x74, 0x65, 0x72, 0x61, 0x6c, 0x12, 0x2c, 0x0a, 0x06, 0x73, 0x79,
-	0x6d, 0x62, 0x6f, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x45, 0x78, 0x70,
-	0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x2e, 0x53, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x48, 0x00,
-	0x52, 0x06, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x12, 0x29, 0x0a, 0x05, 0x75, 0x6e, 0x61, 0x72,
-	0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x45, 0x78, 0x70, 0x72, 0x65, 0x73,
-	0x73, 0x69, 0x6f, 0x6e, 0x2e, 0x55, 0x6e, 0x61, 0x72, 0x79, 0x48, 0x00, 0x52, 0x05, 0x75, 0x6e,
-	0x61, 0x72, 0x79, 0x12, 0x35, 0x0a, 0x09, 0x73, 0x75, 0x62, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c,
-	0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x45, 0x78, 0x70, 0x72, 0x65, 0x73, 0x73,
-	0x69, 0x6f, 0x6e, 0x2e, 0x53, 0x75, 0x62, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x48, 0x00, 0x52,
-	0x09, 0x73, 0x75, 0x62, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x1a, 0x1f, 0x0a, 0x07, 0x4c, 0x69,
-	0x74, 0x65, 0x72, 0x61, 0x6c, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x1a, 0x3d, 0x0a, 0x06, 0x53,
-	0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1f, 0x0a, 0x04, 0x73, 0x69, 0x7a,
-	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0b, 0x2e, 0x53, 0x79, 0x6d, 0x62, 0x6f, 0x6c,
-	0x53, 0x69, 0x7a, 0x65, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x1a, 0x1f, 0x0a, 0x09, 0x53, 0x75,
-	0x62, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x1a, 0x4a, 0x0a, 0x05, 0x55,
-	0x6e, 0x61, 0x72, 0x79, 0x12, 0x1e, 0x0a, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x0e, 0x32, 0x0a, 0x2e, 0x55, 0x6e, 0x61, 0x72, 0x79, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04,
-	0x6b, 0x69, 0x6e, 0x64, 0x12, 0x21, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x45, 0x78, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e,
-	0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x42, 0x07, 0x0a, 0x05, 0x69, 0x6e, 0x6e, 0x65, 0x72,
+	0x67, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x1b, 0x0a, 0x05, 0x74, 0x65, 0x73, 0x74, 0x73, 0x18,
+	0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x05, 0x2e, 0x54, 0x65, 0x73, 0x74, 0x52, 0x05, 0x74, 0x65,
+	0x73, 0x74, 0x73, 0x1a, 0x47, 0x0a, 0x0e, 0x46, 0x72, 0x61, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x73,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x1f, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x09, 0x2e, 0x46, 0x72, 0x61, 0x67, 0x6d, 0x65, 0x6e,
+	0x74, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x56, 0x0a, 0x04,
+	0x54, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x19, 0x0a, 0x04, 0x73, 0x70, 0x61, 0x6e,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x05, 0x2e, 0x53, 0x70, 0x61, 0x6e, 0x52, 0x04, 0x73,
+	0x70, 0x61, 0x6e, 0x12, 0x1f, 0x0a, 0x05, 0x73, 0x74, 0x65, 0x70, 0x73, 0x18, 0x03, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x09, 0x2e, 0x54, 0x65, 0x73, 0x74, 0x53, 0x74, 0x65, 0x70, 0x52, 0x05, 0x73,
+	0x74, 0x65, 0x70, 0x73, 0x22, 0xea, 0x01, 0x0a, 0x08, 0x54, 0x65, 0x73, 0x74, 0x53, 0x74, 0x65,
+	0x70, 0x12, 0x22, 0x0a, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x0e, 0x2e, 0x54, 0x65, 0x73, 0x74, 0x53, 0x74, 0x65, 0x70, 0x2e, 0x4b, 0x69, 0x6e, 0x64, 0x52,
+	0x04, 0x6b, 0x69, 0x6e, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65,
+	0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x72, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65,
+	0x72, 0x12, 0x25, 0x0a, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x45, 0x78, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52,
+	0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x21, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x45, 0x78, 0x70, 0x72, 0x65, 0x73,
+	0x73, 0x69, 0x6f, 0x6e, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x19, 0x0a, 0x04, 0x73,
+	0x70, 0x61, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x05, 0x2e, 0x53, 0x70, 0x61, 0x6e,
+	0x52, 0x04, 0x73, 0x70, 0x61, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x76, 0x72, 0x61, 0x6d, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x76, 0x72, 0x61, 0x6d, 0x22, 0x25, 0x0a, 0x04, 0x4b, 0x69,
+	0x6e, 0x64, 0x12, 0x07, 0x0a, 0x03, 0x53, 0x45, 0x54, 0x10, 0x00, 0x12, 0x08, 0x0a, 0x04, 0x43,
+	0x41, 0x4c, 0x4c, 0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06, 0x45, 0x58, 0x50, 0x45, 0x43, 0x54, 0x10,
+	0x02, 0x22, 0xaa, 0x01, 0x0a, 0x08, 0x46, 0x72, 0x61, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x2d,
+	0x0a, 0x0b, 0x65, 0x78, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x45, 0x78, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e,
+	0x52, 0x0b, 0x65, 0x78, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x16, 0x0a,
+	0x06, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73,
+	0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x12, 0x19, 0x0a, 0x04, 0x73, 0x70, 0x61, 0x6e, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x05, 0x2e, 0x53, 0x70, 0x61, 0x6e, 0x52, 0x04, 0x73, 0x70, 0x61, 0x6e,
+	0x12, 0x22, 0x0a, 0x07, 0x73, 0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x0e, 0x32, 0x08, 0x2e, 0x53, 0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x07, 0x73, 0x65, 0x67,
+	0x6d, 0x65, 0x6e, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x22, 0x6a,
+	0x0a, 0x08, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x66, 0x69,
+	0x6c, 0x65, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x66, 0x69,
+	0x6c, 0x65, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x12, 0x12,
+	0x0a, 0x04, 0x6c, 0x69, 0x6e, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x6c, 0x69,
+	0x6e, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x06, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x22, 0x44, 0x0a, 0x04, 0x53, 0x70,
+	0x61, 0x6e, 0x12, 0x1f, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x72, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x09, 0x2e, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x05, 0x73, 0x74,
+	0x61, 0x72, 0x74, 0x12, 0x1b, 0x0a, 0x03, 0x65, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x09, 0x2e, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x03, 0x65, 0x6e, 0x64,
+	0x22, 0xf9, 0x04, 0x0a, 0x0a, 0x45, 0x78, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12,
+	0x2f, 0x0a, 0x07, 0x6c, 0x69, 0x74, 0x65, 0x72, 0x61, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x13, 0x2e, 0x45, 0x78, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x2e, 0x4c, 0x69,
+	0x74, 0x65, 0x72, 0x61, 0x6c, 0x48, 0x00, 0x52, 0x07, 0x6c, 0x69, 0x74, 0x65, 0x72, 0x61, 0x6c,
+	0x12, 0x2c, 0x0a, 0x06, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x12, 0x2e, 0x45, 0x78, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x2e, 0x53, 0x79,
+	0x6d, 0x62, 0x6f, 0x6c, 0x48, 0x00, 0x52, 0x06, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x12, 0x29,
+	0x0a, 0x05, 0x75, 0x6e, 0x61, 0x72, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e,
+	0x45, 0x78, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x2e, 0x55, 0x6e, 0x61, 0x72, 0x79,
+	0x48, 0x00, 0x52, 0x05, 0x75, 0x6e, 0x61, 0x72, 0x79, 0x12, 0x35, 0x0a, 0x09, 0x73, 0x75, 0x62,
+	0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x45,
+	0x78, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x2e, 0x53, 0x75, 0x62, 0x73, 0x79, 0x6d,
+	0x62, 0x6f, 0x6c, 0x48, 0x00, 0x52, 0x09, 0x73, 0x75, 0x62, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c,
+	0x12, 0x46, 0x0a, 0x10, 0x7a, 0x65, 0x72, 0x6f, 0x5f, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x63, 0x68,
+	0x6f, 0x69, 0x63, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x45, 0x78, 0x70,
+	0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x2e, 0x5a, 0x65, 0x72, 0x6f, 0x50, 0x61, 0x67, 0x65,
+	0x43, 0x68, 0x6f, 0x69, 0x63, 0x65, 0x48, 0x00, 0x52, 0x0e, 0x7a, 0x65, 0x72, 0x6f, 0x50, 0x61,
+	0x67, 0x65, 0x43, 0x68, 0x6f, 0x69, 0x63, 0x65, 0x12, 0x19, 0x0a, 0x04, 0x73, 0x70, 0x61, 0x6e,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x05, 0x2e, 0x53, 0x70, 0x61, 0x6e, 0x52, 0x04, 0x73,
+	0x70, 0x61, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x1a, 0x1f, 0x0a, 0x07, 0x4c, 0x69, 0x74, 0x65, 0x72,
+	0x61, 0x6c, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x1a, 0x3d, 0x0a, 0x06, 0x53, 0x79, 0x6d, 0x62,
+	0x6f, 0x6c, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1f, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0e, 0x32, 0x0b, 0x2e, 0x53, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x53, 0x69, 0x7a,
+	0x65, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x1a, 0x1f, 0x0a, 0x09, 0x53, 0x75, 0x62, 0x73, 0x79,
+	0x6d, 0x62, 0x6f, 0x6c, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x1a, 0x4a, 0x0a, 0x05, 0x55, 0x6e, 0x61, 0x72,
+	0x79, 0x12, 0x1e, 0x0a, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x0a, 0x2e, 0x55, 0x6e, 0x61, 0x72, 0x79, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x6b, 0x69, 0x6e,
+	0x64, 0x12, 0x21, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x0b, 0x2e, 0x45, 0x78, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x1a, 0x5d, 0x0a, 0x0e, 0x5a, 0x65, 0x72, 0x6f, 0x50, 0x61, 0x67, 0x65,
+	0x43, 0x68, 0x6f, 0x69, 0x63, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x7a, 0x65,
+	0x72, 0x6f, 0x5f, 0x70, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x7a,
+	0x65, 0x72, 0x6f, 0x50, 0x61, 0x67, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x61, 0x62, 0x73, 0x6f, 0x6c,
+	0x75, 0x74, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x61, 0x62, 0x73, 0x6f, 0x6c,
+	0x75, 0x74, 0x65, 0x42, 0x07, 0x0a, 0x05, 0x69, 0x6e, 0x6e, 0x65, 0x72, 0x2a, 0x22, 0x0a, 0x07,
+	0x53, 0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x08, 0x0a, 0x04, 0x43, 0x4f, 0x44, 0x45, 0x10,
+	0x00, 0x12, 0x0d, 0x0a, 0x09, 0x5a, 0x45, 0x52, 0x4f, 0x5f, 0x50, 0x41, 0x47, 0x45, 0x10, 0x01,
 	0x2a, 0x22, 0x0a, 0x09, 0x55, 0x6e, 0x61, 0x72, 0x79, 0x54, 0x79, 0x70, 0x65, 0x12, 0x07, 0x0a,
 	0x03, 0x41, 0x44, 0x44, 0x10, 0x00, 0x12, 0x0c, 0x0a, 0x08, 0x53, 0x55, 0x42, 0x54, 0x52, 0x41,
-	0x43, 0x54, 0x10, 0x01, 0x2a, 0x2e, 0x0a, 0x0a, 0x53, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x53, 0x69,
+	0x43, 0x54, 0x10, 0x01, 0x2a, 0x4b, 0x0a, 0x0a, 0x53, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x53, 0x69,
 	0x7a, 0x65, 0x12, 0x08, 0x0a, 0x04, 0x57, 0x4f, 0x52, 0x44, 0x10, 0x00, 0x12, 0x08, 0x0a, 0x04,
 	0x42, 0x59, 0x54, 0x45, 0x10, 0x01, 0x12, 0x0c, 0x0a, 0x08, 0x52, 0x45, 0x4c, 0x41, 0x54, 0x49,
-	0x56, 0x45, 0x10, 0x02, 0x42, 0x0d, 0x5a, 0x0b, 0x53, 0x61, 0x6e, 0x6f, 0x2f, 0x6c, 0x69, 0x6e,
-	0x6b, 0x65, 0x72, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x56, 0x45, 0x10, 0x02, 0x12, 0x08, 0x0a, 0x04, 0x4c, 0x4f, 0x4e, 0x47, 0x10, 0x03, 0x12, 0x11,
+	0x0a, 0x0d, 0x52, 0x45, 0x4c, 0x41, 0x54, 0x49, 0x56, 0x45, 0x5f, 0x4c, 0x4f, 0x4e, 0x47, 0x10,
+	0x04, 0x42, 0x0d, 0x5a, 0x0b, 0x53, 0x61, 0x6e, 0x6f, 0x2f, 0x6c, 0x69, 0x6e, 0x6b, 0x65, 0x72,
+	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -586,36 +1153,56 @@ func file_linker_object_proto_rawDescGZIP() []byte {
 	return file_linker_object_proto_rawDescData
 }
 
-var file_linker_object_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_linker_object_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_linker_object_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_linker_object_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
 var file_linker_object_proto_goTypes = []interface{}{
-	(UnaryType)(0),               // 0: UnaryType
-	(SymbolSize)(0),              // 1: SymbolSize
-	(*Object)(nil),               // 2: Object
-	(*Fragment)(nil),             // 3: Fragment
-	(*Expression)(nil),           // 4: Expression
-	nil,                          // 5: Object.FragmentsEntry
-	(*Expression_Literal)(nil),   // 6: Expression.Literal
-	(*Expression_Symbol)(nil),    // 7: Expression.Symbol
-	(*Expression_Subsymbol)(nil), // 8: Expression.Subsymbol
-	(*Expression_Unary)(nil),     // 9: Expression.Unary
+	(Segment)(0),                      // 0: Segment
+	(UnaryType)(0),                    // 1: UnaryType
+	(SymbolSize)(0),                   // 2: SymbolSize
+	(TestStep_Kind)(0),                // 3: TestStep.Kind
+	(*Object)(nil),                    // 4: Object
+	(*Test)(nil),                      // 5: Test
+	(*TestStep)(nil),                  // 6: TestStep
+	(*Fragment)(nil),                  // 7: Fragment
+	(*Position)(nil),                  // 8: Position
+	(*Span)(nil),                      // 9: Span
+	(*Expression)(nil),                // 10: Expression
+	nil,                               // 11: Object.FragmentsEntry
+	(*Expression_Literal)(nil),        // 12: Expression.Literal
+	(*Expression_Symbol)(nil),         // 13: Expression.Symbol
+	(*Expression_Subsymbol)(nil),      // 14: Expression.Subsymbol
+	(*Expression_Unary)(nil),          // 15: Expression.Unary
+	(*Expression_ZeroPageChoice)(nil), // 16: Expression.ZeroPageChoice
 }
 var file_linker_object_proto_depIdxs = []int32{
-	5,  // 0: Object.fragments:type_name -> Object.FragmentsEntry
-	4,  // 1: Fragment.expressions:type_name -> Expression
-	6,  // 2: Expression.literal:type_name -> Expression.Literal
-	7,  // 3: Expression.symbol:type_name -> Expression.Symbol
-	9,  // 4: Expression.unary:type_name -> Expression.Unary
-	8,  // 5: Expression.subsymbol:type_name -> Expression.Subsymbol
-	3,  // 6: Object.FragmentsEntry.value:type_name -> Fragment
-	1,  // 7: Expression.Symbol.size:type_name -> SymbolSize
-	0,  // 8: Expression.Unary.kind:type_name -> UnaryType
-	4,  // 9: Expression.Unary.value:type_name -> Expression
-	10, // [10:10] is the sub-list for method output_type
-	10, // [10:10] is the sub-list for method input_type
-	10, // [10:10] is the sub-list for extension type_name
-	10, // [10:10] is the sub-list for extension extendee
-	0,  // [0:10] is the sub-list for field type_name
+	11, // 0: Object.fragments:type_name -> Object.FragmentsEntry
+	5,  // 1: Object.tests:type_name -> Test
+	9,  // 2: Test.span:type_name -> Span
+	6,  // 3: Test.steps:type_name -> TestStep
+	3,  // 4: TestStep.kind:type_name -> TestStep.Kind
+	10, // 5: TestStep.address:type_name -> Expression
+	10, // 6: TestStep.value:type_name -> Expression
+	9,  // 7: TestStep.span:type_name -> Span
+	10, // 8: Fragment.expressions:type_name -> Expression
+	9,  // 9: Fragment.span:type_name -> Span
+	0,  // 10: Fragment.segment:type_name -> Segment
+	8,  // 11: Span.start:type_name -> Position
+	8,  // 12: Span.end:type_name -> Position
+	12, // 13: Expression.literal:type_name -> Expression.Literal
+	13, // 14: Expression.symbol:type_name -> Expression.Symbol
+	15, // 15: Expression.unary:type_name -> Expression.Unary
+	14, // 16: Expression.subsymbol:type_name -> Expression.Subsymbol
+	16, // 17: Expression.zero_page_choice:type_name -> Expression.ZeroPageChoice
+	9,  // 18: Expression.span:type_name -> Span
+	7,  // 19: Object.FragmentsEntry.value:type_name -> Fragment
+	2,  // 20: Expression.Symbol.size:type_name -> SymbolSize
+	1,  // 21: Expression.Unary.kind:type_name -> UnaryType
+	10, // 22: Expression.Unary.value:type_name -> Expression
+	23, // [23:23] is the sub-list for method output_type
+	23, // [23:23] is the sub-list for method input_type
+	23, // [23:23] is the sub-list for extension type_name
+	23, // [23:23] is the sub-list for extension extendee
+	0,  // [0:23] is the sub-list for field type_name
 }
 
 func init() { file_linker_object_proto_init() }
@@ -637,7 +1224,7 @@ func file_linker_object_proto_init() {
 			}
 		}
 		file_linker_object_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Fragment); i {
+			switch v := v.(*Test); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -649,7 +1236,19 @@ func file_linker_object_proto_init() {
 			}
 		}
 		file_linker_object_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Expression); i {
+			switch v := v.(*TestStep); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_linker_object_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Fragment); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -661,7 +1260,7 @@ func file_linker_object_proto_init() {
 			}
 		}
 		file_linker_object_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Expression_Literal); i {
+			switch v := v.(*Position); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -673,7 +1272,7 @@ func file_linker_object_proto_init() {
 			}
 		}
 		file_linker_object_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Expression_Symbol); i {
+			switch v := v.(*Span); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -685,6 +1284,42 @@ func file_linker_object_proto_init() {
 			}
 		}
 		file_linker_object_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Expression); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_linker_object_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Expression_Literal); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_linker_object_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Expression_Symbol); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_linker_object_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Expression_Subsymbol); i {
 			case 0:
 				return &v.state
@@ -696,7 +1331,7 @@ func file_linker_object_proto_init() {
 				return nil
 			}
 		}
-		file_linker_object_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+		file_linker_object_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Expression_Unary); i {
 			case 0:
 				return &v.state
@@ -708,20 +1343,33 @@ func file_linker_object_proto_init() {
 				return nil
 			}
 		}
+		file_linker_object_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Expression_ZeroPageChoice); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
-	file_linker_object_proto_msgTypes[2].OneofWrappers = []interface{}{
+	file_linker_object_proto_msgTypes[6].OneofWrappers = []interface{}{
 		(*Expression_Literal_)(nil),
 		(*Expression_Symbol_)(nil),
 		(*Expression_Unary_)(nil),
 		(*Expression_Subsymbol_)(nil),
+		(*Expression_ZeroPageChoice_)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_linker_object_proto_rawDesc,
-			NumEnums:      2,
-			NumMessages:   8,
+			NumEnums:      4,
+			NumMessages:   13,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -0,0 +1,80 @@
+package linker
+
+import "Sano/cpu"
+
+// Summary describes the code of a linked fragment
+type Summary struct {
+	Fragment     string
+	Address      uint16
+	Bytes        int
+	Instructions int
+	// the cycles it takes to run every instruction once, at
+	// the least and at the most when every penalty applies
+	Cycles, MaxCycles int
+}
+
+// Summarize describes every code fragment in a layout, in the order they were placed
+func Summarize(layout *Layout, set cpu.OpcodeSet) []Summary {
+	var ret []Summary
+	for _, p := range layout.Placements {
+		if n := len(ret); n == 0 || ret[n-1].Fragment != p.Fragment {
+			ret = append(ret, Summary{Fragment: p.Fragment, Address: p.Address})
+		}
+		ret[len(ret)-1].Bytes += len(p.Bytes)
+	}
+
+	for i := range ret {
+		s := &ret[i]
+		for _, inst := range decode(layout, set, s.Fragment) {
+			s.Instructions++
+			s.Cycles += inst.Cycles
+			s.MaxCycles += inst.MaxCycles()
+		}
+	}
+	return ret
+}
+
+// decodes the instructions in a linked fragment, skipping its data. Each run of code
+// between data is decoded up to the first thing in it that isn't an instruction.
+func decode(layout *Layout, set cpu.OpcodeSet, fragment string) []cpu.Instruction {
+	var ret []cpu.Instruction
+	// the same way the compiler follows them
+	widths := cpu.RegisterWidths{}
+	run := func(address uint16, size int) {
+		if size == 0 {
+			return
+		}
+		start := int(address - layout.Origin)
+		code := layout.Code[start : start+size]
+		for offset := 0; offset < len(code); {
+			inst, err := set.DisassembleWith(code[offset:], address+uint16(offset), widths)
+			if err != nil {
+				break
+			}
+			if inst.Operation == cpu.REP || inst.Operation == cpu.SEP {
+				widths.Update(inst.Operation, inst.Operand[0])
+			}
+			ret = append(ret, inst)
+			offset += inst.Size()
+		}
+	}
+
+	var address uint16
+	size := 0
+	for _, p := range layout.Placements {
+		if p.Fragment != fragment {
+			continue
+		}
+		if p.Expression.GetData() {
+			run(address, size)
+			size = 0
+			continue
+		}
+		if size == 0 {
+			address = p.Address
+		}
+		size += len(p.Bytes)
+	}
+	run(address, size)
+	return ret
+}
@@ -0,0 +1,95 @@
+package linker
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SymbolTable maps the global names of symbols to their addresses
+//
+// Symbol tables are written as text, starting with the header:
+//
+//	sano symbols 1
+//
+// followed by one line per symbol, sorted by address:
+//
+//	<address> <name>
+//
+// where the address is four-digit hexadecimal and the name is the rest of the line.
+type SymbolTable map[string]uint16
+
+const symbolTableHeader = "sano symbols 1"
+
+// ByAddress returns the names of the symbols at every address
+func (t SymbolTable) ByAddress() map[uint16][]string {
+	ret := map[uint16][]string{}
+	for name, address := range t {
+		ret[address] = append(ret[address], name)
+	}
+	for _, names := range ret {
+		sort.Strings(names)
+	}
+	return ret
+}
+
+func WriteSymbolTable(w io.Writer, t SymbolTable) error {
+	names := make([]string, 0, len(t))
+	for name := range t {
+		if strings.Contains(name, "\n") {
+			return fmt.Errorf("symbol name %q cannot be stored in a symbol table", name)
+		}
+		names = append(names, name)
+	}
+	sortSymbols(names, t)
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, symbolTableHeader)
+	for _, name := range names {
+		fmt.Fprintf(bw, "%04X %s\n", t[name], name)
+	}
+	return bw.Flush()
+}
+
+// sorts names by their address in the table, then by name
+func sortSymbols(names []string, t SymbolTable) {
+	sort.Slice(names, func(i, j int) bool {
+		if t[names[i]] != t[names[j]] {
+			return t[names[i]] < t[names[j]]
+		}
+		return names[i] < names[j]
+	})
+}
+
+func ReadSymbolTable(r io.Reader) (SymbolTable, error) {
+	scanner := bufio.NewScanner(r)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("symbol table is empty")
+	}
+	if scanner.Text() != symbolTableHeader {
+		return nil, fmt.Errorf("not a symbol table, expected header %q but got %q", symbolTableHeader, scanner.Text())
+	}
+
+	t := SymbolTable{}
+	for n := 2; scanner.Scan(); n++ {
+		fields := strings.SplitN(scanner.Text(), " ", 2)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: expected an address and a name", n)
+		}
+		address, err := strconv.ParseUint(fields[0], 16, 16)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: bad address: %w", n, err)
+		}
+		t[fields[1]] = uint16(address)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
@@ -3,11 +3,18 @@ package main
 import (
 	"Sano/compiler"
 	"Sano/cpu"
+	"Sano/diagnostic"
 	"Sano/linker"
 	"Sano/parser"
+	"Sano/tester"
 	"Sano/vera"
+	"Sano/x16"
 	"fmt"
+	"io"
 	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/urfave/cli/v2"
 
@@ -96,7 +103,7 @@ var ConvertImage = &cli.Command{
 			if !ok {
 				return fmt.Errorf("tile-width must be one of 8, 16, 32, or 64, but it was %d", ctx.Int("tile-width"))
 			}
-			err = vera.ExportTile(pal, bpp, w, h, outputFil)egi
+			err = vera.ExportTile(pal, bpp, w, h, outputFile)
 			if err != nil {
 				return fmt.Errorf("failed to export tile data: %w", err)
 			}
@@ -113,54 +120,481 @@ var ConvertImage = &cli.Command{
 	},
 }
 
+// the Commander X16 has a WDC 65C02, so that is the default
+var cpuFlag = &cli.StringFlag{
+	Name:  "cpu",
+	Value: "65c02",
+	Usage: fmt.Sprintf("the CPU to assemble for (%s)", strings.Join(cpu.TargetNames(), ", ")),
+}
+
+func targetOpcodes(ctx *cli.Context) (cpu.OpcodeSet, error) {
+	set, ok := cpu.Targets[strings.ToLower(ctx.String("cpu"))]
+	if !ok {
+		return nil, fmt.Errorf("unknown cpu %q, expected one of %s", ctx.String("cpu"), strings.Join(cpu.TargetNames(), ", "))
+	}
+	return set, nil
+}
+
+var defineFlag = &cli.StringSliceFlag{
+	Name:    "define",
+	Aliases: []string{"D"},
+	Usage:   "define a constant for conditional blocks to check, as NAME=value or just NAME for 1",
+}
+
+var includeFlag = &cli.StringSliceFlag{
+	Name:    "include",
+	Aliases: []string{"I"},
+	Usage:   "also look for included files in this directory, after the directory of the file including them",
+}
+
+var diagnosticsFlag = &cli.StringFlag{
+	Name:  "diagnostics",
+	Value: "text",
+	Usage: "how to write errors in the source to stderr: text, with the lines they're in, or json for editors",
+	Action: func(ctx *cli.Context, format string) error {
+		if format != "text" && format != "json" {
+			return fmt.Errorf("unknown diagnostics format %q, expected text or json", format)
+		}
+		return nil
+	},
+}
+
+// writes the problems found in the source to w in the format asked for, text or json. If any
+// of them are errors, the error returned exits with a failure without saying anything more.
+func report(w io.Writer, format string, problems []diagnostic.Diagnostic, read []linker.Source) error {
+	if len(problems) == 0 {
+		return nil
+	}
+	if format == "json" {
+		if err := diagnostic.WriteJSON(w, problems); err != nil {
+			return err
+		}
+	} else {
+		texts := map[string][]byte{}
+		for _, s := range read {
+			texts[s.Filename] = s.Text
+		}
+		for _, d := range problems {
+			if err := diagnostic.Render(w, d, texts); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, d := range problems {
+		if d.Severity == diagnostic.Error {
+			return cli.Exit("", 1)
+		}
+	}
+	return nil
+}
+
+func compilationProblems(errs []compiler.CompilationError) []diagnostic.Diagnostic {
+	var ret []diagnostic.Diagnostic
+	for _, err := range errs {
+		ret = append(ret, err.Diagnostic())
+	}
+	return ret
+}
+
+// the linker's view of the files a loader read, for listings and test failures
+func sources(loader *parser.Loader) []linker.Source {
+	var ret []linker.Source
+	for _, s := range loader.Sources {
+		ret = append(ret, linker.Source{Filename: s.Filename, Text: s.Text})
+	}
+	return ret
+}
+
+func defines(ctx *cli.Context) (map[string]int, error) {
+	ret := map[string]int{}
+	for _, define := range ctx.StringSlice("define") {
+		name, value, ok := strings.Cut(define, "=")
+		if !ok {
+			ret[name] = 1
+			continue
+		}
+		n, err := strconv.ParseInt(value, 0, 64)
+		if err != nil {
+			return nil, fmt.Errorf("the value of %s must be a number, but it was %q", name, value)
+		}
+		ret[name] = int(n)
+	}
+	return ret, nil
+}
+
 var Assembler = &cli.Command{
 	Name:  "assembler",
 	Usage: "WIP assembler and linker",
+	Flags: []cli.Flag{
+		cpuFlag,
+		defineFlag,
+		includeFlag,
+		diagnosticsFlag,
+		&cli.StringFlag{
+			Name:    "lines",
+			Aliases: []string{"l"},
+			Usage:   "also write a table mapping addresses to source lines to this file",
+		},
+		&cli.StringFlag{
+			Name:    "symbols",
+			Aliases: []string{"s"},
+			Usage:   "also write the addresses of all symbols to this file",
+		},
+		&cli.StringFlag{
+			Name:    "object",
+			Aliases: []string{"c"},
+			Usage:   "also write the unlinked object to this file",
+		},
+		&cli.StringFlag{
+			Name:  "listing",
+			Usage: "also write a listing of the source with the addresses and bytes it assembled to to this file",
+		},
+		&cli.BoolFlag{
+			Name:  "cycles",
+			Usage: "annotate the listing with the cycles every instruction takes",
+		},
+		&cli.BoolFlag{
+			Name:  "summary",
+			Usage: "print the size of every fragment and the cycles it takes to run through",
+		},
+	},
 	Action: func(ctx *cli.Context) error {
-		data, err := os.ReadFile(ctx.Args().Get(0))
+		set, err := targetOpcodes(ctx)
 		if err != nil {
-			return fmt.Errorf("failed to read input file: %w", err)
+			return err
+		}
+		constants, err := defines(ctx)
+		if err != nil {
+			return err
+		}
+
+		loader := &parser.Loader{IncludePaths: ctx.StringSlice("include")}
+		g, err := loader.Load(ctx.Args().Get(0))
+		if syntax, ok := err.(parser.Errors); ok {
+			return report(ctx.App.ErrWriter, ctx.String("diagnostics"), syntax.Diagnostics(), sources(loader))
 		}
-		g, err := parser.Parser.ParseBytes(ctx.Args().Get(0), data)
 		if err != nil {
 			return fmt.Errorf("failed to parse input file: %w", err)
 		}
 
-		cx16 := cpu.Base6502Opcodes.And(cpu.WDC65C02ExtensionOpcodes)
-		c := compiler.Compiler{Instructions: cx16}
+		c := compiler.Compiler{Instructions: set, Defines: constants}
 		obj, errors := c.Compile(g)
-		if len(errors) > 0 {
-			for _, err := range errors {
-				println(err.String())
+		if err := report(ctx.App.ErrWriter, ctx.String("diagnostics"), compilationProblems(errors), sources(loader)); err != nil {
+			return err
+		}
+
+		if ctx.IsSet("object") {
+			objectFile, err := os.Create(ctx.String("object"))
+			if err != nil {
+				return fmt.Errorf("failed to open object file: %w", err)
+			}
+			defer objectFile.Close()
+
+			err = linker.WriteObject(objectFile, obj)
+			if err != nil {
+				return fmt.Errorf("failed to write object file: %w", err)
 			}
-			os.Exit(1)
 		}
 
-		prg, err := linker.LinkToPrg([]*linker.Object{obj})
+		prg, layout, err := linker.LinkToPrg([]*linker.Object{obj})
+		if linkErrors, ok := err.(linker.LinkErrors); ok {
+			return report(ctx.App.ErrWriter, ctx.String("diagnostics"), linkErrors.Diagnostics(), sources(loader))
+		}
 		if err != nil {
 			return fmt.Errorf("failed to link file into prg: %w", err)
 		}
+		var unused []diagnostic.Diagnostic
+		for _, u := range layout.Unused {
+			unused = append(unused, u.Diagnostic())
+		}
+		if err := report(ctx.App.ErrWriter, ctx.String("diagnostics"), unused, sources(loader)); err != nil {
+			return err
+		}
+
+		if ctx.IsSet("lines") {
+			linesFile, err := os.Create(ctx.String("lines"))
+			if err != nil {
+				return fmt.Errorf("failed to open line table file: %w", err)
+			}
+			defer linesFile.Close()
+
+			err = linker.WriteLineTable(linesFile, layout.Lines)
+			if err != nil {
+				return fmt.Errorf("failed to write line table: %w", err)
+			}
+		}
+
+		if ctx.IsSet("symbols") {
+			symbolsFile, err := os.Create(ctx.String("symbols"))
+			if err != nil {
+				return fmt.Errorf("failed to open symbol table file: %w", err)
+			}
+			defer symbolsFile.Close()
+
+			err = linker.WriteSymbolTable(symbolsFile, layout.Symbols)
+			if err != nil {
+				return fmt.Errorf("failed to write symbol table: %w", err)
+			}
+		}
 
 		err = os.WriteFile(ctx.Args().Get(1), prg, 0660)
 		if err != nil {
 			return fmt.Errorf("failed to write prg file: %w", err)
 		}
 
+		if ctx.IsSet("listing") {
+			listingFile, err := os.Create(ctx.String("listing"))
+			if err != nil {
+				return fmt.Errorf("failed to open listing file: %w", err)
+			}
+			defer listingFile.Close()
+
+			var cycles cpu.OpcodeSet
+			if ctx.Bool("cycles") {
+				cycles = set
+			}
+			err = linker.WriteListing(listingFile, layout, sources(loader), cycles)
+			if err != nil {
+				return fmt.Errorf("failed to write listing: %w", err)
+			}
+		}
+
+		if ctx.Bool("summary") {
+			return writeSummary(os.Stdout, linker.Summarize(layout, set))
+		}
+
 		return nil
 	},
 }
 
+var Objdump = &cli.Command{
+	Name:  "objdump",
+	Usage: "describe the contents of an object file",
+	Flags: []cli.Flag{
+		cpuFlag,
+	},
+	Action: func(ctx *cli.Context) error {
+		set, err := targetOpcodes(ctx)
+		if err != nil {
+			return err
+		}
+
+		file, err := os.Open(ctx.Args().Get(0))
+		if err != nil {
+			return fmt.Errorf("failed to open object file: %w", err)
+		}
+		defer file.Close()
+
+		obj, err := linker.ReadObject(file)
+		if err != nil {
+			return err
+		}
+
+		return linker.Dump(os.Stdout, obj, set)
+	},
+}
+
+var Disasm = &cli.Command{
+	Name:  "disasm",
+	Usage: "disassemble a prg file or raw binary into sano source",
+	Flags: []cli.Flag{
+		cpuFlag,
+		&cli.BoolFlag{
+			Name:  "raw",
+			Usage: "the input is a raw binary without a load address",
+		},
+		&cli.UintFlag{
+			Name:  "origin",
+			Value: linker.PrgCodeAddress,
+			Usage: "the address a raw binary is loaded at",
+		},
+		&cli.StringFlag{
+			Name:    "symbols",
+			Aliases: []string{"s"},
+			Usage:   "a symbol table to take labels from",
+		},
+		&cli.BoolFlag{
+			Name:  "cycles",
+			Usage: "annotate every instruction with the cycles it takes",
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		set, err := targetOpcodes(ctx)
+		if err != nil {
+			return err
+		}
+
+		data, err := os.ReadFile(ctx.Args().Get(0))
+		if err != nil {
+			return fmt.Errorf("failed to read input file: %w", err)
+		}
+
+		var origin uint16
+		var code []byte
+		if ctx.Bool("raw") {
+			if ctx.Uint("origin") > 0xFFFF {
+				return fmt.Errorf("origin must be a 16-bit address, but it was %d", ctx.Uint("origin"))
+			}
+			origin, code = uint16(ctx.Uint("origin")), data
+		} else {
+			origin, code, err = linker.SplitPrg(data)
+			if err != nil {
+				return err
+			}
+		}
+
+		entry := origin
+		if sys, start, rest, ok := linker.SkipBasicStub(origin, code); ok {
+			entry, origin, code = sys, start, rest
+		}
+
+		symbols := linker.SymbolTable{}
+		if ctx.IsSet("symbols") {
+			file, err := os.Open(ctx.String("symbols"))
+			if err != nil {
+				return fmt.Errorf("failed to open symbol table: %w", err)
+			}
+			defer file.Close()
+
+			symbols, err = linker.ReadSymbolTable(file)
+			if err != nil {
+				return fmt.Errorf("failed to read symbol table: %w", err)
+			}
+		}
+
+		return writeDisassembly(os.Stdout, set, code, origin, entry, symbols, ctx.Bool("cycles"))
+	},
+}
+
+var Test = &cli.Command{
+	Name:      "test",
+	Usage:     "run the test blocks in source files on a simulated CPU",
+	ArgsUsage: "files...",
+	Flags: []cli.Flag{
+		cpuFlag,
+		defineFlag,
+		includeFlag,
+		diagnosticsFlag,
+		&cli.StringFlag{
+			Name:  "machine",
+			Value: "x16",
+			Usage: "what the CPU is connected to: x16 for the Commander X16's memory map and VERA, or bare for 64K of RAM",
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		set, err := targetOpcodes(ctx)
+		if err != nil {
+			return err
+		}
+		var machine func() cpu.Bus
+		switch ctx.String("machine") {
+		case "x16":
+			machine = func() cpu.Bus { return x16.New(x16.DefaultRAMBanks) }
+		case "bare":
+			machine = tester.Bare
+		default:
+			return fmt.Errorf("unknown machine %q, expected x16 or bare", ctx.String("machine"))
+		}
+		constants, err := defines(ctx)
+		if err != nil {
+			return err
+		}
+		if ctx.NArg() == 0 {
+			return fmt.Errorf("no files to test")
+		}
+
+		var objs []*linker.Object
+		var read []linker.Source
+		var problems []diagnostic.Diagnostic
+		for _, filename := range ctx.Args().Slice() {
+			loader := &parser.Loader{IncludePaths: ctx.StringSlice("include")}
+			g, err := loader.Load(filename)
+			read = append(read, sources(loader)...)
+			if syntax, ok := err.(parser.Errors); ok {
+				problems = append(problems, syntax.Diagnostics()...)
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("failed to parse input file: %w", err)
+			}
+
+			c := compiler.Compiler{Instructions: set, Defines: constants}
+			obj, errors := c.Compile(g)
+			problems = append(problems, compilationProblems(errors)...)
+			objs = append(objs, obj)
+		}
+		if err := report(ctx.App.ErrWriter, ctx.String("diagnostics"), problems, read); err != nil {
+			return err
+		}
+
+		layout, err := linker.LinkTests(objs, linker.PrgCodeAddress)
+		if linkErrors, ok := err.(linker.LinkErrors); ok {
+			return report(ctx.App.ErrWriter, ctx.String("diagnostics"), linkErrors.Diagnostics(), read)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to link tests: %w", err)
+		}
+
+		var tests []*linker.Test
+		for _, obj := range objs {
+			tests = append(tests, obj.Tests...)
+		}
+		return writeResults(os.Stdout, tester.Run(tests, layout, set, read, machine))
+	},
+}
+
+func writeResults(w io.Writer, results []*tester.Result) error {
+	failures := 0
+	for _, r := range results {
+		if r.Passed() {
+			fmt.Fprintf(w, "ok    %s (%d cycles)\n", r.Name, r.Cycles)
+			continue
+		}
+		failures++
+		fmt.Fprintf(w, "FAIL  %s\n", r.Name)
+		for _, f := range r.Failures {
+			fmt.Fprintf(w, "      %s\n", strings.ReplaceAll(f, "\n", "\n      "))
+		}
+	}
+	if failures > 0 {
+		return fmt.Errorf("%d of %d tests failed", failures, len(results))
+	}
+	return nil
+}
+
+func writeSummary(w io.Writer, summaries []linker.Summary) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "fragment\taddress\tbytes\tinstructions\tcycles")
+	for _, s := range summaries {
+		cycles := fmt.Sprint(s.Cycles)
+		if s.MaxCycles != s.Cycles {
+			cycles = fmt.Sprintf("%d-%d", s.Cycles, s.MaxCycles)
+		}
+		fmt.Fprintf(tw, "%s\t$%04X\t%d\t%d\t%s\n", s.Fragment, s.Address, s.Bytes, s.Instructions, cycles)
+	}
+	return tw.Flush()
+}
+
 func main() {
 	app := &cli.App{
 		Name:  "sano",
 		Usage: "commander x16 developer's toolbox",
 		ExitErrHandler: func(cCtx *cli.Context, err error) {
-			if err != nil {
-				fmt.Println(err.Error())
-				os.Exit(1)
+			if err == nil {
+				return
+			}
+			// problems in the source have been reported already, with nothing more to say
+			if message := err.Error(); message != "" {
+				fmt.Fprintln(os.Stderr, message)
+			}
+			code := 1
+			if exit, ok := err.(cli.ExitCoder); ok {
+				code = exit.ExitCode()
 			}
+			os.Exit(code)
 		},
-		Commands: []*cli.Command{ConvertImage, Assembler},
+		Commands: []*cli.Command{ConvertImage, Assembler, Objdump, Disasm, Test},
 	}
 	app.Run(os.Args)
 }
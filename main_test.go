@@ -0,0 +1,45 @@
+package main
+
+import (
+	"Sano/diagnostic"
+	"Sano/linker"
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli/v2"
+)
+
+func TestReportingProblems(t *testing.T) {
+	read := []linker.Source{{Filename: "test.san", Text: []byte("@main { rts !; }\n")}}
+	warning := diagnostic.Diagnostic{
+		Severity: diagnostic.Warning,
+		Code:     "W000",
+		Message:  "just so you know",
+		Primary:  diagnostic.Label{Filename: "test.san", Line: 1, Column: 9, EndLine: 1, EndColumn: 15},
+	}
+	failure := warning
+	failure.Severity = diagnostic.Error
+	failure.Message = "this is wrong"
+
+	var out strings.Builder
+	if err := report(&out, "text", nil, read); err != nil || out.Len() > 0 {
+		t.Errorf("with no problems, wrote %q and returned %v", out.String(), err)
+	}
+
+	out.Reset()
+	if err := report(&out, "text", []diagnostic.Diagnostic{warning}, read); err != nil {
+		t.Errorf("warnings shouldn't fail, but returned %v", err)
+	}
+	if !strings.Contains(out.String(), "warning[W000]: just so you know") || !strings.Contains(out.String(), "@main { rts !; }") {
+		t.Errorf("wrote %q", out.String())
+	}
+
+	out.Reset()
+	err := report(&out, "json", []diagnostic.Diagnostic{warning, failure}, read)
+	if exit, ok := err.(cli.ExitCoder); !ok || exit.ExitCode() != 1 || exit.Error() != "" {
+		t.Errorf("errors should exit quietly with 1, but returned %v", err)
+	}
+	if !strings.HasPrefix(out.String(), `[{"severity":"warning"`) || !strings.Contains(out.String(), `"message":"this is wrong"`) {
+		t.Errorf("wrote %q", out.String())
+	}
+}
@@ -0,0 +1,26 @@
+package parser
+
+import "github.com/alecthomas/participle/v2/lexer"
+
+// ConditionalDeclarations are only compiled if their condition is true,
+// or if it's false, the ones after .else are instead
+type ConditionalDeclarations struct {
+	Pos lexer.Position
+
+	Condition ConstantExpression       `"." "if" @@ "{"`
+	Then      Declarations             `@@ "}"`
+	ElseIf    *ConditionalDeclarations `( "." "else" ( @@`
+	Else      *Declarations            `| "{" @@ "}" ) )?`
+}
+
+// ConditionalStatements are the same as ConditionalDeclarations, but in a fragment or macro
+type ConditionalStatements struct {
+	Pos lexer.Position
+
+	Condition ConstantExpression     `"." "if" @@ "{"`
+	Then      []Statement            `@@* "}"`
+	ElseIf    *ConditionalStatements `( "." "else" ( @@`
+	Else      []Statement            `| "{" @@* "}" ) )?`
+}
+
+func (ConditionalStatements) isStatement() {}
@@ -0,0 +1,56 @@
+package parser
+
+import "github.com/alecthomas/participle/v2/lexer"
+
+// ConstantExpression is worked out while compiling, from numbers and constants but not
+// symbols, since where they are isn't known until linking. Comparisons and logical
+// operators give 1 for true and 0 for false, and anything that isn't 0 counts as true.
+type ConstantExpression struct {
+	Pos lexer.Position
+
+	Left  Conjunction   `@@`
+	Right []Conjunction `( "|" "|" @@ )*`
+}
+
+type Conjunction struct {
+	Left  Comparison   `@@`
+	Right []Comparison `( "&" "&" @@ )*`
+}
+
+type Comparison struct {
+	Left     Sum    `@@`
+	Operator string `( @( "=" "=" | "!" "=" | "<" "=" | ">" "=" | "<" | ">" )`
+	Right    *Sum   `  @@ )?`
+}
+
+type Sum struct {
+	Left  Product   `@@`
+	Right []SumTerm `@@*`
+}
+
+type SumTerm struct {
+	Operator string  `@( "+" | "-" )`
+	Value    Product `@@`
+}
+
+type Product struct {
+	Left  ConstantValue   `@@`
+	Right []ProductFactor `@@*`
+}
+
+type ProductFactor struct {
+	Operator string        `@( "*" | "/" | "%" )`
+	Value    ConstantValue `@@`
+}
+
+type ConstantValue struct {
+	Pos    lexer.Position
+	EndPos lexer.Position
+
+	Not      *ConstantValue      `  "!" @@`
+	Negate   *ConstantValue      `| "-" @@`
+	Defined  string              `| "defined" "(" @Ident ")"`
+	Number   *int                `| @Int`
+	Constant string              `| @Ident`
+	Group    *ConstantExpression `| "(" @@ ")"`
+}
@@ -0,0 +1,27 @@
+package parser
+
+import "github.com/alecthomas/participle/v2/lexer"
+
+// Data is bytes or little-endian words put straight into a fragment
+type Data struct {
+	Pos    lexer.Position
+	EndPos lexer.Position
+
+	Size   string       `"." @( "byte" | "word" )`
+	Values []Expression `@@ ( "," @@ )* ";"`
+}
+
+func (Data) isStatement() {}
+
+// Repeat compiles its statements a number of times, with the index
+// counting up from 0 as a constant that they can use
+type Repeat struct {
+	Pos    lexer.Position
+	EndPos lexer.Position
+
+	Index      string             `"." "repeat" ( @Ident "," )?`
+	Count      ConstantExpression `@@ "{"`
+	Statements []Statement        `@@* "}"`
+}
+
+func (Repeat) isStatement() {}
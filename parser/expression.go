@@ -7,16 +7,19 @@ import (
 
 var Expressions = participle.Union[Expression](
 	NumericLiteral{},
+	Computed{},
 	Symbol{},
 )
 
 type Expression interface {
 	Position() lexer.Position
+	EndPosition() lexer.Position
 	isExpression()
 }
 
 type NumericLiteral struct {
 	Pos    lexer.Position
+	EndPos lexer.Position
 	Number int `@Int`
 }
 
@@ -24,15 +27,41 @@ func (n NumericLiteral) Position() lexer.Position {
 	return n.Pos
 }
 
+func (n NumericLiteral) EndPosition() lexer.Position {
+	return n.EndPos
+}
+
 func (NumericLiteral) isExpression() {}
 
 type Symbol struct {
-	Pos  lexer.Position
-	Name string `@Ident`
+	Pos    lexer.Position
+	EndPos lexer.Position
+	Name   string `@Ident`
 }
 
 func (s Symbol) Position() lexer.Position {
 	return s.Pos
 }
 
+func (s Symbol) EndPosition() lexer.Position {
+	return s.EndPos
+}
+
 func (Symbol) isExpression() {}
+
+// Computed is a constant expression in brackets, which is worked out while compiling
+type Computed struct {
+	Pos    lexer.Position
+	EndPos lexer.Position
+	Value  ConstantExpression `"(" @@ ")"`
+}
+
+func (c Computed) Position() lexer.Position {
+	return c.Pos
+}
+
+func (c Computed) EndPosition() lexer.Position {
+	return c.EndPos
+}
+
+func (Computed) isExpression() {}
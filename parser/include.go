@@ -0,0 +1,163 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/alecthomas/participle/v2/lexer"
+)
+
+// Include declares everything in another file where the include is
+type Include struct {
+	Pos    lexer.Position
+	EndPos lexer.Position
+
+	Path string `"." "include" @String ";"`
+
+	// the included file, which is filled in by a Loader. Files that are included
+	// more than once share the same one.
+	File *File
+	// why the file couldn't be included, when the include is in a conditional block.
+	// It's only a problem if the block is chosen, which the compiler decides.
+	Err *IncludeError
+}
+
+// Source is the text of a file that was read by a Loader
+type Source struct {
+	Filename string
+	Text     []byte
+}
+
+// Loader parses files along with the files that they include
+type Loader struct {
+	// IncludePaths are searched in order for included files that
+	// aren't next to the file including them
+	IncludePaths []string
+	// Sources has every file that was read, in the order they were read in
+	Sources []Source
+
+	files  map[string]*File
+	errors Errors
+}
+
+// IncludeError is a file that couldn't be included, at the include or at what's wrong in the file
+type IncludeError struct {
+	Pos, EndPos lexer.Position
+	Message     string
+}
+
+func (e *IncludeError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Pos, e.Message)
+}
+
+// Load parses a file and every file that it includes, including the ones in conditional
+// blocks, since which blocks are chosen isn't known until it's compiled. The syntax errors
+// in all of them and the IncludeErrors are returned together as Errors, along with what could
+// be recovered, except that files in conditional blocks that can't be included are left in
+// their Include's Err. Only problems reading the file itself are returned on their own.
+func (l *Loader) Load(filename string) (*File, error) {
+	l.errors = nil
+	f, err := l.load(filename, nil, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(l.errors) > 0 {
+		return f, l.errors
+	}
+	return f, nil
+}
+
+// stack has the absolute paths of the files being loaded, to find files that include themselves,
+// and conditional is whether any of them included the next in a conditional block
+func (l *Loader) load(filename string, stack []string, conditional bool) (*File, error) {
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, err
+	}
+	for i, it := range stack {
+		if it == abs {
+			cycle := append(append([]string{}, stack[i:]...), abs)
+			return nil, fmt.Errorf("the files include each other in a cycle: %s", strings.Join(cycle, " -> "))
+		}
+	}
+	if f, ok := l.files[abs]; ok {
+		return f, nil
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	l.Sources = append(l.Sources, Source{Filename: filename, Text: data})
+	f, errs := ParseRecovering(filename, data)
+	l.errors = append(l.errors, errs...)
+	if f == nil {
+		f = &File{}
+	}
+	l.resolve(filename, &f.Declarations, append(stack, abs), conditional)
+
+	if l.files == nil {
+		l.files = map[string]*File{}
+	}
+	l.files[abs] = f
+	return f, nil
+}
+
+// loads the files included in d, carrying on past the ones that can't be
+func (l *Loader) resolve(filename string, d *Declarations, stack []string, conditional bool) {
+	for i := range d.Includes {
+		include := &d.Includes[i]
+		fail := func(err *IncludeError) {
+			if conditional {
+				include.Err = err
+			} else {
+				l.errors = append(l.errors, err)
+			}
+		}
+
+		path, err := l.find(filename, include.Path)
+		if err != nil {
+			fail(&IncludeError{include.Pos, include.EndPos, err.Error()})
+			continue
+		}
+		f, err := l.load(path, stack, conditional)
+		if err != nil {
+			fail(&IncludeError{include.Pos, include.EndPos, fmt.Sprintf("failed to include %s: %s", include.Path, err)})
+			continue
+		}
+		if f.CPU != nil {
+			fail(&IncludeError{f.CPU.Pos, f.CPU.EndPos, fmt.Sprintf("only the file being assembled can have a .cpu directive, but %s has one", path)})
+			continue
+		}
+		include.File = f
+	}
+
+	for i := range d.Conditionals {
+		for it := &d.Conditionals[i]; it != nil; it = it.ElseIf {
+			l.resolve(filename, &it.Then, stack, true)
+			if it.Else != nil {
+				l.resolve(filename, it.Else, stack, true)
+			}
+		}
+	}
+}
+
+// where an included file is, looking next to the file including it before the include paths
+func (l *Loader) find(from, path string) (string, error) {
+	if filepath.IsAbs(path) {
+		return path, nil
+	}
+	for _, dir := range append([]string{filepath.Dir(from)}, l.IncludePaths...) {
+		candidate := filepath.Join(dir, path)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+	}
+	return "", fmt.Errorf("couldn't find %s next to %s or in any of the include paths", path, from)
+}
@@ -0,0 +1,132 @@
+package parser
+
+import (
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/participle/v2"
+	"github.com/alecthomas/participle/v2/lexer"
+)
+
+// Lexer splits source into tokens, skipping Go style comments. Numbers are all Int tokens, and
+// can be written in decimal, in hexadecimal with 0x or $, in binary with 0b or %, or as an ASCII
+// character in single quotes. In a constant expression, a % after a number, a name or a closing
+// bracket is the remainder operator instead, so (a %10) is a remainder and (a * %10) is binary.
+var Lexer lexer.Definition = &binaryDefinition{lexer.MustSimple([]lexer.SimpleRule{
+	{Name: "Comment", Pattern: `//[^\n]*|/\*([^*]|\*+[^*/])*\*+/`},
+	{Name: "Whitespace", Pattern: `\s+`},
+	{Name: "String", Pattern: `"(\\.|[^"\\\n])*"`},
+	{Name: "Int", Pattern: `\$[0-9A-Fa-f]+|'(\\x[0-9A-Fa-f]{2}|\\.|[^'\\\n])'|0[xX][0-9A-Fa-f]+|0[bB][01]+|[0-9]+`},
+	{Name: "Ident", Pattern: `[A-Za-z_][A-Za-z0-9_]*`},
+	{Name: "Punct", Pattern: `[^\sA-Za-z0-9_]`},
+})}
+
+// binaryDefinition joins a % to the 0s and 1s straight after it into a binary Int
+// token, unless the % is the remainder operator
+type binaryDefinition struct {
+	lexer.Definition
+}
+
+func (d *binaryDefinition) Lex(filename string, r io.Reader) (lexer.Lexer, error) {
+	lex, err := d.Definition.Lex(filename, r)
+	if err != nil {
+		return nil, err
+	}
+	symbols := d.Symbols()
+	return &binaryLexer{
+		lexer:  lex,
+		int:    symbols["Int"],
+		ident:  symbols["Ident"],
+		punct:  symbols["Punct"],
+		elided: map[lexer.TokenType]bool{symbols["Comment"]: true, symbols["Whitespace"]: true},
+	}, nil
+}
+
+type binaryLexer struct {
+	lexer             lexer.Lexer
+	int, ident, punct lexer.TokenType
+	elided            map[lexer.TokenType]bool
+	next              *lexer.Token
+
+	// the last token that wasn't elided
+	previous lexer.Token
+	// how deep in brackets this token is, and whether it's in the condition
+	// of a .if or the count of a .repeat, which is where constant expressions are
+	brackets int
+	header   bool
+}
+
+func (l *binaryLexer) Next() (lexer.Token, error) {
+	t, err := l.read()
+	if err != nil {
+		return t, err
+	}
+	if t.Type == l.punct && t.Value == "%" && !l.remainder() {
+		next, err := l.read()
+		if err != nil {
+			return next, err
+		}
+		if next.Type == l.int && next.Pos.Offset == t.Pos.Offset+1 && strings.Trim(next.Value, "01") == "" {
+			t = lexer.Token{Type: l.int, Value: "%" + next.Value, Pos: t.Pos}
+		} else {
+			l.next = &next
+		}
+	}
+	if !l.elided[t.Type] {
+		l.track(t)
+	}
+	return t, nil
+}
+
+func (l *binaryLexer) read() (lexer.Token, error) {
+	if l.next != nil {
+		t := *l.next
+		l.next = nil
+		return t, nil
+	}
+	return l.lexer.Next()
+}
+
+// whether a % here comes after a value in a constant expression
+func (l *binaryLexer) remainder() bool {
+	if l.brackets == 0 && !l.header {
+		return false
+	}
+	return l.previous.Type == l.int || l.previous.Type == l.ident || l.previous.Value == ")"
+}
+
+func (l *binaryLexer) track(t lexer.Token) {
+	switch {
+	case t.Value == "(":
+		l.brackets++
+	case t.Value == ")" && l.brackets > 0:
+		l.brackets--
+	case t.Value == "{":
+		l.header = false
+	case t.Type == l.ident && (t.Value == "if" || t.Value == "repeat") && l.previous.Value == ".":
+		l.header = true
+	}
+	l.previous = t
+}
+
+// rewrites numbers in the notations that strconv doesn't understand
+// into ones that it does, for them to be captured as ints
+func numbers(t lexer.Token) (lexer.Token, error) {
+	switch t.Value[0] {
+	case '$':
+		t.Value = "0x" + t.Value[1:]
+	case '%':
+		t.Value = "0b" + t.Value[1:]
+	case '\'':
+		c, _, _, err := strconv.UnquoteChar(t.Value[1:len(t.Value)-1], '\'')
+		if err != nil {
+			return t, participle.Errorf(t.Pos, "invalid character %s", t.Value)
+		}
+		if c > 0x7F {
+			return t, participle.Errorf(t.Pos, "only ASCII characters can be numbers, but %s isn't one", t.Value)
+		}
+		t.Value = strconv.Itoa(int(c))
+	}
+	return t, nil
+}
@@ -0,0 +1,31 @@
+package parser
+
+import "github.com/alecthomas/participle/v2/lexer"
+
+// Macro is a list of statements that can be pasted into fragments with
+// %name(arguments);, with the parameters standing for the arguments
+type Macro struct {
+	Pos    lexer.Position
+	EndPos lexer.Position
+
+	Name       string      `"." "macro" @Ident "("`
+	Parameters []Parameter `( @@ ( "," @@ )* )? ")" "{"`
+	Statements []Statement `@@* "}"`
+}
+
+type Parameter struct {
+	Pos    lexer.Position
+	EndPos lexer.Position
+
+	Name string `@Ident`
+}
+
+type MacroInvocation struct {
+	Pos    lexer.Position
+	EndPos lexer.Position
+
+	Name      string       `"%" @Ident "("`
+	Arguments []Expression `( @@ ( "," @@ )* )? ")" ";"`
+}
+
+func (MacroInvocation) isStatement() {}
@@ -8,7 +8,8 @@ import (
 )
 
 type OpcodeInvocation struct {
-	Pos lexer.Position
+	Pos    lexer.Position
+	EndPos lexer.Position
 
 	Opcode  string  `@Ident`
 	Address Address `@@ ";"`
@@ -20,16 +21,28 @@ func (OpcodeInvocation) isStatement() {
 
 var Addresses = participle.Union[Address](
 	AddrAccumulator{},
+	AddrBlockMove{},
 	AddrImmediate{},
+	AddrAbsoluteLongIndexedX{},
+	AddrAbsoluteLong{},
+	AddrAbsoluteIndirectLong{},
+	AddrZeroPageIndirectLongIndexedY{},
+	AddrZeroPageIndirectLong{},
+	AddrStackRelativeIndirectIndexedY{},
+	AddrStackRelative{},
+	AddrAbsoluteXIndexedIndirect{},
 	AddrAbsoluteIndirect{},
 	AddrAbsoluteIndexedX{},
 	AddrAbsoluteIndexedY{},
 	AddrAbsoluteAddress{},
 	AddrZeroPageXIndexedIndirect{},
 	AddrZeroPageIndirectYIndex{},
+	AddrZeroPageIndirect{},
 	AddrZeroPageXIndexed{},
 	AddrZeroPageYIndexed{},
+	AddrZeroPageRelative{},
 	AddrZeroPage{},
+	AddrAutomatic{},
 	AddrRelative{},
 	AddrImplied{},
 )
@@ -62,6 +75,12 @@ type AddrAbsoluteIndirect struct {
 
 func (AddrAbsoluteIndirect) AddressingMode() cpu.Mode { return cpu.Indirect }
 
+type AddrAbsoluteXIndexedIndirect struct {
+	Address Expression `"(" "=" @@ "," "x" ")"`
+}
+
+func (AddrAbsoluteXIndexedIndirect) AddressingMode() cpu.Mode { return cpu.AbsoluteIndexedIndirect }
+
 type AddrAbsoluteAddress struct {
 	Address Expression `"=" @@`
 }
@@ -110,9 +129,105 @@ type AddrZeroPageIndirectYIndex struct {
 
 func (AddrZeroPageIndirectYIndex) AddressingMode() cpu.Mode { return cpu.IndirectYIndexed }
 
+type AddrZeroPageIndirect struct {
+	Address Expression `"(" ":" @@ ")"`
+}
+
+func (AddrZeroPageIndirect) AddressingMode() cpu.Mode { return cpu.ZeroPageIndirect }
+
+type AddrZeroPageRelative struct {
+	Address Expression `":" @@ ","`
+	Target  Expression `"~" @@`
+}
+
+func (AddrZeroPageRelative) AddressingMode() cpu.Mode { return cpu.ZeroPageRelative }
+
+// AddrAutomatic is an address without a sigil, which is zero page
+// if it can be and absolute otherwise
+type AddrAutomatic struct {
+	Address Expression `@@`
+	Index   string     `( "," @( "x" | "y" ) )?`
+}
+
+func (a AddrAutomatic) AddressingMode() cpu.Mode {
+	switch a.Index {
+	case "x":
+		return cpu.AbsoluteIndexedX
+	case "y":
+		return cpu.AbsoluteIndexedY
+	default:
+		return cpu.Absolute
+	}
+}
+
+// ZeroPageMode is the addressing mode used when the address is in zero page
+func (a AddrAutomatic) ZeroPageMode() cpu.Mode {
+	switch a.Index {
+	case "x":
+		return cpu.ZeroPageIndexedX
+	case "y":
+		return cpu.ZeroPageIndexedY
+	default:
+		return cpu.ZeroPage
+	}
+}
+
 type AddrRelative struct {
 	Sign    string     `"~"`
 	Address Expression `@@`
 }
 
 func (AddrRelative) AddressingMode() cpu.Mode { return cpu.Relative }
+
+type AddrBlockMove struct {
+	Source      Expression `"#" @@ ","`
+	Destination Expression `"#" @@`
+}
+
+func (AddrBlockMove) AddressingMode() cpu.Mode { return cpu.BlockMove }
+
+type AddrAbsoluteLong struct {
+	Address Expression `"=" "=" @@`
+}
+
+func (AddrAbsoluteLong) AddressingMode() cpu.Mode { return cpu.AbsoluteLong }
+
+type AddrAbsoluteLongIndexedX struct {
+	Address Expression `"=" "=" @@ "," "x"`
+}
+
+func (AddrAbsoluteLongIndexedX) AddressingMode() cpu.Mode { return cpu.AbsoluteLongIndexedX }
+
+type AddrAbsoluteIndirectLong struct {
+	Address Expression `"[" "=" @@ "]"`
+}
+
+func (AddrAbsoluteIndirectLong) AddressingMode() cpu.Mode { return cpu.AbsoluteIndirectLong }
+
+type AddrZeroPageIndirectLong struct {
+	Address Expression `"[" ":" @@ "]"`
+}
+
+func (AddrZeroPageIndirectLong) AddressingMode() cpu.Mode { return cpu.ZeroPageIndirectLong }
+
+type AddrZeroPageIndirectLongIndexedY struct {
+	Address Expression `"[" ":" @@ "]" "," "y"`
+}
+
+func (AddrZeroPageIndirectLongIndexedY) AddressingMode() cpu.Mode {
+	return cpu.ZeroPageIndirectLongIndexedY
+}
+
+type AddrStackRelative struct {
+	Offset Expression `":" @@ "," "s"`
+}
+
+func (AddrStackRelative) AddressingMode() cpu.Mode { return cpu.StackRelative }
+
+type AddrStackRelativeIndirectIndexedY struct {
+	Offset Expression `"(" ":" @@ "," "s" ")" "," "y"`
+}
+
+func (AddrStackRelativeIndirectIndexedY) AddressingMode() cpu.Mode {
+	return cpu.StackRelativeIndirectIndexedY
+}
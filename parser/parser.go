@@ -9,16 +9,51 @@ var Parser = participle.MustBuild[File](
 	Addresses,
 	Statements,
 	Expressions,
-	participle.UseLookahead(3),
+	TestSteps,
+	participle.Lexer(Lexer),
+	participle.Elide("Comment", "Whitespace"),
+	participle.Map(numbers, "Int"),
+	participle.UseLookahead(participle.MaxLookahead),
+	participle.Unquote("String"),
 )
 
 type File struct {
-	Fragment []Fragment `@@*`
+	CPU *CPUDirective `@@?`
+	Declarations
+}
+
+// Declarations are everything in a file apart from its directives
+type Declarations struct {
+	Fragment     []Fragment                `( @@`
+	ZeroPage     []ZeroPageFragment        `| @@`
+	Macros       []Macro                   `| @@`
+	Tests        []Test                    `| @@`
+	Includes     []Include                 `| @@`
+	Conditionals []ConditionalDeclarations `| @@ )*`
+}
+
+// CPUDirective declares the CPU that the code in a file is written for
+type CPUDirective struct {
+	Pos    lexer.Position
+	EndPos lexer.Position
+
+	Name string `"." "cpu" @String ";"`
 }
 
 type Fragment struct {
-	Pos lexer.Position
+	Pos    lexer.Position
+	EndPos lexer.Position
 
 	Name       string      `"@" @Ident "{"`
 	Statements []Statement `(@@)* "}"`
 }
+
+// ZeroPageFragment reserves bytes in zero page, which
+// instructions can refer to with zero page addressing
+type ZeroPageFragment struct {
+	Pos    lexer.Position
+	EndPos lexer.Position
+
+	Name string `"@" @Ident "zeropage"`
+	Size int    `@Int ";"`
+}
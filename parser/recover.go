@@ -0,0 +1,139 @@
+package parser
+
+import (
+	"Sano/diagnostic"
+	"bytes"
+	"errors"
+	"strings"
+
+	"github.com/alecthomas/participle/v2"
+	"github.com/alecthomas/participle/v2/lexer"
+)
+
+// MaxErrors is how many syntax errors ParseRecovering finds before it gives up
+const MaxErrors = 100
+
+// The diagnostic codes of syntax errors and IncludeErrors
+const (
+	CodeSyntax  = "E001"
+	CodeInclude = "E002"
+)
+
+// Errors are syntax errors and IncludeErrors found in one or more files
+type Errors []error
+
+// Diagnostics describes the errors for showing with the source they're in
+func (e Errors) Diagnostics() []diagnostic.Diagnostic {
+	ret := make([]diagnostic.Diagnostic, len(e))
+	for i, err := range e {
+		ret[i] = diagnostic.Diagnostic{Severity: diagnostic.Error, Code: CodeSyntax, Message: err.Error()}
+		var ierr *IncludeError
+		var perr participle.Error
+		if errors.As(err, &ierr) {
+			ret[i].Code = CodeInclude
+			ret[i].Message = ierr.Message
+			ret[i].Primary = diagnostic.Label{
+				Filename:  ierr.Pos.Filename,
+				Line:      ierr.Pos.Line,
+				Column:    ierr.Pos.Column,
+				EndLine:   ierr.EndPos.Line,
+				EndColumn: ierr.EndPos.Column,
+			}
+		} else if errors.As(err, &perr) {
+			pos := perr.Position()
+			ret[i].Message = perr.Message()
+			ret[i].Primary = diagnostic.Label{Filename: pos.Filename, Line: pos.Line, Column: pos.Column}
+		}
+	}
+	return ret
+}
+
+func (e Errors) Error() string {
+	var messages []string
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+// ParseRecovering parses a file, carrying on after syntax errors to find as many as it can. After
+// each one, the statement it's in is skipped up to the next ; or }, and the file is parsed again.
+// The file it returns is what's left after skipping, which is nil if nothing could be recovered.
+func ParseRecovering(filename string, data []byte) (*File, Errors) {
+	var errs Errors
+	source := append([]byte{}, data...)
+	for len(errs) < MaxErrors {
+		f, err := Parser.ParseBytes(filename, source)
+		if err == nil {
+			return f, errs
+		}
+		errs = append(errs, err)
+
+		var perr participle.Error
+		if !errors.As(err, &perr) || !skip(filename, source, perr.Position().Offset) {
+			break
+		}
+	}
+	return nil, errs
+}
+
+// blanks out the statement with a syntax error at offset, from the last ;, { or } before it up to
+// the next ; or }, leaving the lines and columns of everything else where they were. A } is only
+// skipped when it's where the error is and there's nothing else to skip, so that the braces
+// around the statement still match.
+func skip(filename string, source []byte, offset int) bool {
+	lex, err := Lexer.Lex(filename, bytes.NewReader(source))
+	if err != nil {
+		return false
+	}
+	tokens, err := lexer.ConsumeAll(lex)
+	if err != nil {
+		return false
+	}
+	punct := Lexer.Symbols()["Punct"]
+
+	start, end := 0, -1
+	for _, t := range tokens {
+		if t.Type != punct || (t.Value != ";" && t.Value != "{" && t.Value != "}") {
+			continue
+		}
+		if t.Pos.Offset < offset {
+			start = t.Pos.Offset + 1
+			continue
+		}
+		if t.Value == "{" {
+			continue
+		}
+		end = t.Pos.Offset
+		if t.Value == ";" {
+			end++
+		}
+		break
+	}
+	if end == -1 {
+		// there's nowhere to carry on from
+		return false
+	}
+
+	if blank(source[start:end]) {
+		return true
+	}
+	// the } itself is what's wrong
+	return end == offset && blank(source[end:end+1])
+}
+
+// replaces everything but line breaks with spaces, returning whether there was anything but whitespace
+func blank(text []byte) bool {
+	skipped := false
+	for i, c := range text {
+		switch c {
+		case '\n':
+		case ' ', '\t', '\r':
+			text[i] = ' '
+		default:
+			text[i] = ' '
+			skipped = true
+		}
+	}
+	return skipped
+}
@@ -0,0 +1,64 @@
+package parser
+
+import (
+	"testing"
+)
+
+func TestParseRecovering(t *testing.T) {
+	source := `// comments are skipped
+@main {
+	lda #1 2;
+	/* and so are
+	   block comments */ ldx #2;
+	sta =$9F20 =;
+	rts !;
+}
+@other {
+	lda #;
+	nop
+}
+@last { rts !; }`
+	f, errs := ParseRecovering("test.san", []byte(source))
+	expected := []string{
+		`test.san:3:9: unexpected token "2" (expected ";")`,
+		`test.san:6:13: unexpected token "=" (expected ";")`,
+		`test.san:10:7: unexpected token ";" (expected Expression)`,
+		`test.san:12:1: unexpected token "}" (expected Address ";")`,
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("expected %d errors, but got %d: %s", len(expected), len(errs), errs)
+	}
+	for i, err := range errs {
+		if err.Error() != expected[i] {
+			t.Errorf("got %q, expected %q", err.Error(), expected[i])
+		}
+	}
+
+	// everything apart from the statements with errors in them is still there
+	if f == nil {
+		t.Fatal("expected the rest of the file to be recovered")
+	}
+	if len(f.Fragment) != 3 {
+		t.Fatalf("expected 3 fragments, but got %d", len(f.Fragment))
+	}
+	for i, count := range []int{2, 0, 1} {
+		if got := len(f.Fragment[i].Statements); got != count {
+			t.Errorf("expected %s to have %d statements, but it had %d", f.Fragment[i].Name, count, got)
+		}
+	}
+	if pos := f.Fragment[2].Pos; pos.Line != 13 || pos.Column != 1 {
+		t.Errorf("expected the last fragment to stay at 13:1, but it moved to %d:%d", pos.Line, pos.Column)
+	}
+}
+
+func TestParseRecoveringGivesUp(t *testing.T) {
+	// there's nothing after the error to carry on from
+	f, errs := ParseRecovering("test.san", []byte("@main { rts !;"))
+	if f != nil || len(errs) != 1 {
+		t.Fatalf("expected one error and no file, but got %d errors", len(errs))
+	}
+
+	if _, errs := ParseRecovering("test.san", []byte("@main { rts !; }")); len(errs) != 0 {
+		t.Fatalf("expected no errors, but got %s", errs)
+	}
+}
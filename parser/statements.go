@@ -1,9 +1,16 @@
 package parser
 
-import "github.com/alecthomas/participle/v2"
+import (
+	"github.com/alecthomas/participle/v2"
+	"github.com/alecthomas/participle/v2/lexer"
+)
 
 var Statements = participle.Union[Statement](
 	SymbolDeclaration{},
+	ConditionalStatements{},
+	Repeat{},
+	Data{},
+	MacroInvocation{},
 	OpcodeInvocation{},
 )
 
@@ -12,6 +19,9 @@ type Statement interface {
 }
 
 type SymbolDeclaration struct {
+	Pos    lexer.Position
+	EndPos lexer.Position
+
 	Name string `"&" @Ident ":"`
 }
 
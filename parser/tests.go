@@ -0,0 +1,63 @@
+package parser
+
+import (
+	"github.com/alecthomas/participle/v2"
+	"github.com/alecthomas/participle/v2/lexer"
+)
+
+// Test is a test of the code in a file, which sano test runs
+// by setting up registers and memory, calling fragments, and
+// checking what they did
+type Test struct {
+	Pos    lexer.Position
+	EndPos lexer.Position
+
+	Name  string     `"." "test" @String "{"`
+	Steps []TestStep `@@* "}"`
+}
+
+var TestSteps = participle.Union[TestStep](
+	TestSet{},
+	TestCall{},
+	TestExpect{},
+)
+
+type TestStep interface {
+	isTestStep()
+}
+
+// TestLocation is a register or flag by name, memory at an address, or VRAM at an address
+type TestLocation struct {
+	VRAM     Expression `  "vram" @@`
+	Register string     `| @Ident`
+	Address  Expression `| ( ":" | "=" ) @@`
+}
+
+type TestSet struct {
+	Pos    lexer.Position
+	EndPos lexer.Position
+
+	Location TestLocation `"set" @@ ","`
+	Value    Expression   `"#" @@ ";"`
+}
+
+func (TestSet) isTestStep() {}
+
+type TestCall struct {
+	Pos    lexer.Position
+	EndPos lexer.Position
+
+	Target Expression `"call" @@ ";"`
+}
+
+func (TestCall) isTestStep() {}
+
+type TestExpect struct {
+	Pos    lexer.Position
+	EndPos lexer.Position
+
+	Location TestLocation `"expect" @@ ","`
+	Value    Expression   `"#" @@ ";"`
+}
+
+func (TestExpect) isTestStep() {}
@@ -0,0 +1,257 @@
+package tester
+
+import (
+	"Sano/cpu"
+	"Sano/linker"
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Result is what happened when a test was run
+type Result struct {
+	Name string
+	Span *linker.Span
+	// what went wrong, each followed by the line of source it was about
+	Failures []string
+	// how many cycles the calls in the test took altogether
+	Cycles uint64
+}
+
+func (r *Result) Passed() bool {
+	return len(r.Failures) == 0
+}
+
+// CallCycleLimit is how long a call can run for before it's assumed to never return
+const CallCycleLimit = 10_000_000
+
+// where calls return to, which is past anything the linker places
+const returnAddress = 0xFFF0
+
+var flags = map[string]byte{
+	"c": cpu.FlagCarry,
+	"z": cpu.FlagZero,
+	"i": cpu.FlagInterrupt,
+	"d": cpu.FlagDecimal,
+	"v": cpu.FlagOverflow,
+	"n": cpu.FlagNegative,
+}
+
+// VideoBus is a bus with VERA's video memory behind it, which tests can check
+type VideoBus interface {
+	cpu.Bus
+	VideoRAM() []byte
+}
+
+// Bare makes a machine with nothing but 64K of RAM
+func Bare() cpu.Bus {
+	return &cpu.Memory{}
+}
+
+// Run runs every test against the layout on a simulated CPU running set, each on a machine
+// of its own made by calling machine. The sources are used to quote lines in failure messages.
+func Run(tests []*linker.Test, layout *linker.Layout, set cpu.OpcodeSet, sources []linker.Source, machine func() cpu.Bus) []*Result {
+	r := runner{layout: layout, set: set, sources: map[string][]byte{}, machine: machine}
+	for _, source := range sources {
+		r.sources[source.Filename] = source.Text
+	}
+
+	results := make([]*Result, len(tests))
+	for i, test := range tests {
+		results[i] = r.run(test)
+	}
+	return results
+}
+
+type runner struct {
+	layout  *linker.Layout
+	set     cpu.OpcodeSet
+	sources map[string][]byte
+	machine func() cpu.Bus
+}
+
+func (r *runner) run(test *linker.Test) *Result {
+	result := &Result{Name: test.Name, Span: test.Span}
+	fail := func(position *linker.Position, format string, args ...any) {
+		message := fmt.Sprintf("%s: %s", linker.FormatPosition(position), fmt.Sprintf(format, args...))
+		if line, ok := r.line(position); ok {
+			message += "\n\t" + line
+		}
+		result.Failures = append(result.Failures, message)
+	}
+
+	bus := r.machine()
+	for i, b := range r.layout.Code {
+		bus.Write(r.layout.Origin+uint16(i), b)
+	}
+	c := cpu.NewCPU(r.set, bus)
+	c.Reset()
+	c.S = 0xFF
+
+	// the memory a step sets or checks, or nil if it's a register
+	memory := func(step *linker.TestStep) (func() byte, func(byte), bool) {
+		address, err := r.layout.Evaluate(step.Address)
+		if err != nil {
+			fail(step.Span.Start, "%s", err)
+			return nil, nil, false
+		}
+		if !step.Vram {
+			return func() byte { return bus.Read(uint16(address)) },
+				func(v byte) { bus.Write(uint16(address), v) }, true
+		}
+		video, ok := bus.(VideoBus)
+		if !ok {
+			fail(step.Span.Start, "the machine tests are running on has no VRAM")
+			return nil, nil, false
+		}
+		vram := video.VideoRAM()
+		if int(address) >= len(vram) {
+			fail(step.Span.Start, "$%05X is past the end of VRAM", address)
+			return nil, nil, false
+		}
+		return func() byte { return vram[address] }, func(v byte) { vram[address] = v }, true
+	}
+
+	for _, step := range test.Steps {
+		switch step.Kind {
+		case linker.TestStep_SET:
+			value, err := r.layout.Evaluate(step.Value)
+			if err != nil {
+				fail(step.Span.Start, "%s", err)
+				return result
+			}
+			if step.Address != nil {
+				_, write, ok := memory(step)
+				if !ok {
+					return result
+				}
+				write(byte(value))
+			} else {
+				setRegister(c, step.Register, byte(value))
+			}
+		case linker.TestStep_EXPECT:
+			expected, err := r.layout.Evaluate(step.Value)
+			if err != nil {
+				fail(step.Span.Start, "%s", err)
+				return result
+			}
+			if step.Address != nil {
+				read, _, ok := memory(step)
+				if !ok {
+					return result
+				}
+				if actual := read(); actual != byte(expected) {
+					fail(step.Span.Start, "expected %s to be $%02X, but it was $%02X", r.describe(step), byte(expected), actual)
+				}
+			} else if _, ok := flags[step.Register]; ok {
+				if actual := register(c, step.Register); (actual != 0) != (expected != 0) {
+					fail(step.Span.Start, "expected %s to be %d, but it was %d", step.Register, boolToInt(expected != 0), actual)
+				}
+			} else if actual := register(c, step.Register); actual != byte(expected) {
+				fail(step.Span.Start, "expected %s to be $%02X, but it was $%02X", step.Register, byte(expected), actual)
+			}
+		case linker.TestStep_CALL:
+			target, err := r.layout.Evaluate(step.Address)
+			if err != nil {
+				fail(step.Span.Start, "%s", err)
+				return result
+			}
+			cycles, err := r.call(c, uint16(target))
+			result.Cycles += cycles
+			if err != nil {
+				position := step.Span.Start
+				if at, ok := r.layout.Lines.Lookup(c.PC); ok {
+					position = at
+				}
+				fail(position, "%s", err)
+				return result
+			}
+		default:
+			panic("unhandled case")
+		}
+	}
+
+	return result
+}
+
+// runs the code at target as a subroutine until it returns
+func (r *runner) call(c *cpu.CPU, target uint16) (uint64, error) {
+	// the same as JSR, which pushes the address of its last byte
+	pushed := uint16(returnAddress - 1)
+	c.Bus.Write(0x100|uint16(c.S), byte(pushed>>8))
+	c.Bus.Write(0x100|uint16(c.S-1), byte(pushed))
+	c.S -= 2
+	c.PC = target
+
+	start := c.Cycles
+	for c.PC != returnAddress {
+		if c.Cycles-start > CallCycleLimit {
+			return c.Cycles - start, fmt.Errorf("the call to $%04X didn't return after %d cycles", target, CallCycleLimit)
+		}
+		if _, err := c.Step(); err != nil {
+			return c.Cycles - start, err
+		}
+	}
+	return c.Cycles - start, nil
+}
+
+// how failure messages refer to the memory a step checks
+func (r *runner) describe(step *linker.TestStep) string {
+	address, _ := r.layout.Evaluate(step.Address)
+	if step.Vram {
+		return fmt.Sprintf("VRAM $%05X", address)
+	}
+	return fmt.Sprintf("$%04X", address)
+}
+
+// the text of the line at a position, if it's in one of the sources
+func (r *runner) line(position *linker.Position) (string, bool) {
+	text, ok := r.sources[position.Filename]
+	if !ok {
+		return "", false
+	}
+	lines := bytes.Split(text, []byte("\n"))
+	if position.Line < 1 || int(position.Line) > len(lines) {
+		return "", false
+	}
+	return strings.TrimSpace(string(lines[position.Line-1])), true
+}
+
+func register(c *cpu.CPU, name string) byte {
+	switch name {
+	case "a":
+		return c.A
+	case "x":
+		return c.X
+	case "y":
+		return c.Y
+	}
+	if c.P&flags[name] != 0 {
+		return 1
+	}
+	return 0
+}
+
+func setRegister(c *cpu.CPU, name string, value byte) {
+	switch name {
+	case "a":
+		c.A = value
+	case "x":
+		c.X = value
+	case "y":
+		c.Y = value
+	default:
+		if value != 0 {
+			c.P |= flags[name]
+		} else {
+			c.P &^= flags[name]
+		}
+	}
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
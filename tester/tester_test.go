@@ -0,0 +1,167 @@
+package tester
+
+import (
+	"Sano/compiler"
+	"Sano/cpu"
+	"Sano/linker"
+	"Sano/parser"
+	"Sano/x16"
+	"strings"
+	"testing"
+)
+
+func runTests(t *testing.T, machine func() cpu.Bus, source string) []*Result {
+	t.Helper()
+
+	f, err := parser.Parser.ParseString("test.san", source)
+	if err != nil {
+		t.Fatalf("failed to parse: %s", err)
+	}
+	c := compiler.Compiler{Instructions: cpu.WDC65C02Opcodes}
+	obj, errs := c.Compile(f)
+	for _, err := range errs {
+		t.Error(err.String())
+	}
+	if len(errs) > 0 {
+		t.FailNow()
+	}
+	layout, err := linker.LinkTests([]*linker.Object{obj}, linker.PrgCodeAddress)
+	if err != nil {
+		t.Fatalf("failed to link: %s", err)
+	}
+	sources := []linker.Source{{Filename: "test.san", Text: []byte(source)}}
+	return Run(obj.Tests, layout, cpu.WDC65C02Opcodes, sources, machine)
+}
+
+const clamp = `@clamp {
+	cpx =limit;
+	bcc ~done;
+	ldx =limit;
+	&done:
+		rts !;
+}
+
+@limit zeropage 1;
+`
+
+func TestPassingTests(t *testing.T) {
+	results := runTests(t, Bare, clamp+`
+.test "below the limit" {
+	set :limit, #10;
+	set x, #3;
+	call clamp;
+	expect x, #3;
+	expect c, #0;
+}
+
+.test "above the limit" {
+	set :limit, #10;
+	set x, #30;
+	call clamp;
+	expect x, #10;
+	expect =limit, #10;
+}
+`)
+	if len(results) != 2 {
+		t.Fatalf("ran %d tests", len(results))
+	}
+	for _, r := range results {
+		if !r.Passed() {
+			t.Errorf("%s failed: %s", r.Name, strings.Join(r.Failures, "\n"))
+		}
+		if r.Cycles == 0 {
+			t.Errorf("%s took no cycles", r.Name)
+		}
+	}
+}
+
+func TestFailuresQuoteTheSource(t *testing.T) {
+	results := runTests(t, Bare, clamp+`
+.test "wrong" {
+	set :limit, #10;
+	set x, #30;
+	call clamp;
+	expect x, #30;
+}
+`)
+	if results[0].Passed() {
+		t.Fatalf("the test passed")
+	}
+	expected := "test.san:15:2: expected x to be $1E, but it was $0A\n\texpect x, #30;"
+	if len(results[0].Failures) != 1 || results[0].Failures[0] != expected {
+		t.Errorf("failed with %q", results[0].Failures)
+	}
+}
+
+func TestCallsThatDontReturn(t *testing.T) {
+	results := runTests(t, Bare, `@forever {
+	&loop:
+		bra ~loop;
+}
+
+.test "forever" {
+	call forever;
+}
+`)
+	if results[0].Passed() || !strings.HasPrefix(results[0].Failures[0], "test.san:3:3: the call to") {
+		t.Errorf("failed with %q", results[0].Failures)
+	}
+}
+
+func TestCheckingVRAM(t *testing.T) {
+	source := `@poke {
+	stz =0x9F25;
+	stz =0x9F20;
+	stz =0x9F21;
+	lda #0x10;
+	sta =0x9F22;
+	stx =0x9F23;
+	sty =0x9F23;
+	rts !;
+}
+
+.test "poke" {
+	set x, #1;
+	set y, #2;
+	call poke;
+	expect vram 0, #1;
+	expect vram 1, #2;
+}
+`
+	results := runTests(t, func() cpu.Bus { return x16.New(x16.DefaultRAMBanks) }, source)
+	if !results[0].Passed() {
+		t.Errorf("failed: %s", strings.Join(results[0].Failures, "\n"))
+	}
+
+	results = runTests(t, Bare, source)
+	if results[0].Passed() || !strings.Contains(results[0].Failures[0], "has no VRAM") {
+		t.Errorf("failed with %q", results[0].Failures)
+	}
+}
+
+func TestCheckingVRAMAboveTheFirstBank(t *testing.T) {
+	// the program in main.san
+	source := `@main {
+	stz =0x9F25;
+	lda #0x0B;
+	sta =0x9F20;
+	lda #0xFA;
+	sta =0x9F21;
+	lda #0x01;
+	sta =0x9F22;
+	lda #0x0A;
+	sta =0x9F23;
+	rts !;
+}
+
+.test "main sets up the palette" {
+	call main;
+	expect vram 0x1FA0B, #0x0A;
+	expect =0x9F22, #0x01;
+}
+`
+	results := runTests(t, func() cpu.Bus { return x16.New(x16.DefaultRAMBanks) }, source)
+	if !results[0].Passed() {
+		t.Errorf("failed: %s", strings.Join(results[0].Failures, "\n"))
+	}
+}
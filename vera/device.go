@@ -0,0 +1,168 @@
+package vera
+
+// VRAMSize is how much video memory VERA has, which takes 17 bits to address
+const VRAMSize = 0x20000
+
+// The registers VERA has, as offsets from where it's mapped in, which is $9F20 on the X16
+const (
+	RegisterAddrL = iota
+	RegisterAddrM
+	RegisterAddrH
+	RegisterData0
+	RegisterData1
+	RegisterCtrl
+	RegisterIEN
+	RegisterISR
+	RegisterIRQLineL
+	// these four are different registers depending on DCSEL in CTRL
+	RegisterDC0
+	RegisterDC1
+	RegisterDC2
+	RegisterDC3
+
+	// RegisterCount is how many bytes of address space the registers take up
+	RegisterCount = 0x20
+)
+
+// Bits of CTRL
+const (
+	CtrlAddrSel = 0x01
+	CtrlDCSel   = 0x7E
+	CtrlReset   = 0x80
+)
+
+// Bits of ADDR_H
+const (
+	AddrHHigh      = 0x01
+	AddrHDecrement = 0x08
+	AddrHIncrement = 0xF0
+)
+
+// how far each value of ADDR_H's increment field moves the address
+var increments = [16]uint32{0, 1, 2, 4, 8, 16, 32, 64, 128, 256, 512, 40, 80, 160, 320, 640}
+
+// Device models VERA's registers and video memory, with the two data ports that read
+// and write VRAM at an address that moves on by itself after every access. The display
+// composer has its first two sets of registers (DCSEL 0 and 1), and the rest of the
+// registers just hold what was written to them.
+type Device struct {
+	VRAM [VRAMSize]byte
+
+	// the address and ADDR_H increment settings of each data port
+	addr  [2]uint32
+	addrH [2]byte
+	ctrl  byte
+	// DC_VIDEO, DC_HSCALE, DC_VSCALE and DC_BORDER, then DC_HSTART, DC_HSTOP, DC_VSTART and DC_VSTOP
+	dc        [2][4]byte
+	registers [RegisterCount]byte
+}
+
+// NewDevice makes a VERA in the state it powers up in
+func NewDevice() *Device {
+	d := &Device{}
+	d.Reset()
+	return d
+}
+
+// Reset puts the registers back how they are when VERA powers up, leaving VRAM as it was
+func (d *Device) Reset() {
+	d.addr = [2]uint32{}
+	d.addrH = [2]byte{}
+	d.ctrl = 0
+	d.registers = [RegisterCount]byte{}
+	// a 640x480 display, scaled 1:1
+	d.dc = [2][4]byte{
+		{0x00, 0x80, 0x80, 0x00},
+		{0x00, 640 >> 2, 0x00, 480 >> 1},
+	}
+}
+
+// Address is where a data port reads and writes VRAM next
+func (d *Device) Address(port int) uint32 {
+	return d.addr[port]
+}
+
+// DisplayComposer is the value of one of the DC registers, numbered
+// the same way as DCSEL, or 0 for the ones that aren't modelled
+func (d *Device) DisplayComposer(dcsel int, register int) byte {
+	if dcsel < 0 || dcsel >= len(d.dc) {
+		return 0
+	}
+	return d.dc[dcsel][register]
+}
+
+// Register is the value of one of the registers after the DC ones, which don't do anything by themselves
+func (d *Device) Register(register int) byte {
+	return d.registers[register]
+}
+
+func (d *Device) port() int {
+	return int(d.ctrl & CtrlAddrSel)
+}
+
+func (d *Device) dcsel() int {
+	return int(d.ctrl&CtrlDCSel) >> 1
+}
+
+// moves a port's address on after an access to its data register
+func (d *Device) step(port int) {
+	by := increments[d.addrH[port]>>4]
+	if d.addrH[port]&AddrHDecrement != 0 {
+		d.addr[port] -= by
+	} else {
+		d.addr[port] += by
+	}
+	d.addr[port] %= VRAMSize
+}
+
+// Read reads a register, which moves the data port's address on if it's DATA0 or DATA1
+func (d *Device) Read(register uint16) byte {
+	switch register {
+	case RegisterAddrL:
+		return byte(d.addr[d.port()])
+	case RegisterAddrM:
+		return byte(d.addr[d.port()] >> 8)
+	case RegisterAddrH:
+		return d.addrH[d.port()]&^AddrHHigh | byte(d.addr[d.port()]>>16)
+	case RegisterData0, RegisterData1:
+		port := int(register - RegisterData0)
+		value := d.VRAM[d.addr[port]]
+		d.step(port)
+		return value
+	case RegisterCtrl:
+		return d.ctrl
+	case RegisterDC0, RegisterDC1, RegisterDC2, RegisterDC3:
+		return d.DisplayComposer(d.dcsel(), int(register-RegisterDC0))
+	default:
+		return d.registers[register%RegisterCount]
+	}
+}
+
+// Write writes a register, which writes VRAM and moves the data port's address on if it's DATA0 or DATA1
+func (d *Device) Write(register uint16, value byte) {
+	switch register {
+	case RegisterAddrL:
+		d.addr[d.port()] = d.addr[d.port()]&^0xFF | uint32(value)
+	case RegisterAddrM:
+		d.addr[d.port()] = d.addr[d.port()]&^0xFF00 | uint32(value)<<8
+	case RegisterAddrH:
+		d.addr[d.port()] = d.addr[d.port()]&0xFFFF | uint32(value&AddrHHigh)<<16
+		d.addrH[d.port()] = value
+	case RegisterData0, RegisterData1:
+		port := int(register - RegisterData0)
+		d.VRAM[d.addr[port]] = value
+		d.step(port)
+	case RegisterCtrl:
+		if value&CtrlReset != 0 {
+			d.Reset()
+			return
+		}
+		d.ctrl = value
+	case RegisterDC0, RegisterDC1, RegisterDC2, RegisterDC3:
+		if dcsel := d.dcsel(); dcsel < len(d.dc) {
+			d.dc[dcsel][register-RegisterDC0] = value
+		}
+	default:
+		d.registers[register%RegisterCount] = value
+	}
+}
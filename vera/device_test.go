@@ -0,0 +1,53 @@
+package vera
+
+import "testing"
+
+func TestDataPortsIncrement(t *testing.T) {
+	d := NewDevice()
+	d.Write(RegisterAddrL, 0xFF)
+	d.Write(RegisterAddrM, 0xFF)
+	// increment by 1, in the upper 64K
+	d.Write(RegisterAddrH, 0x11)
+	d.Write(RegisterData0, 0xAA)
+	d.Write(RegisterData0, 0xBB)
+	if d.VRAM[0x1FFFF] != 0xAA || d.VRAM[0x00000] != 0xBB {
+		t.Errorf("wrote $%02X and $%02X", d.VRAM[0x1FFFF], d.VRAM[0x00000])
+	}
+	if d.Address(0) != 0x00001 {
+		t.Errorf("the address wrapped around to $%05X", d.Address(0))
+	}
+
+	// port 1, decrementing by 40
+	d.Write(RegisterCtrl, CtrlAddrSel)
+	d.Write(RegisterAddrL, 80)
+	d.Write(RegisterAddrM, 0)
+	d.Write(RegisterAddrH, 0xB8)
+	d.VRAM[40] = 0x12
+	d.Read(RegisterData1)
+	if value := d.Read(RegisterData1); value != 0x12 || d.Address(1) != 0 {
+		t.Errorf("read $%02X, leaving the address at $%05X", value, d.Address(1))
+	}
+	if d.Address(0) != 0x00001 {
+		t.Errorf("port 1 moved port 0 to $%05X", d.Address(0))
+	}
+	if d.Read(RegisterAddrH) != 0xB8 {
+		t.Errorf("ADDR_H reads back as $%02X", d.Read(RegisterAddrH))
+	}
+}
+
+func TestDisplayComposerRegisters(t *testing.T) {
+	d := NewDevice()
+	d.Write(RegisterDC1, 0x40)
+	d.Write(RegisterCtrl, 1<<1)
+	if d.Read(RegisterDC1) != 640>>2 {
+		t.Errorf("DC_HSTOP is $%02X", d.Read(RegisterDC1))
+	}
+	if d.DisplayComposer(0, 1) != 0x40 {
+		t.Errorf("DC_HSCALE is $%02X", d.DisplayComposer(0, 1))
+	}
+
+	d.Write(RegisterCtrl, CtrlReset)
+	if d.DisplayComposer(0, 1) != 0x80 {
+		t.Errorf("reset left DC_HSCALE at $%02X", d.DisplayComposer(0, 1))
+	}
+}
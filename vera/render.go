@@ -0,0 +1,195 @@
+package vera
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+)
+
+// PaletteAddress is where VERA's palette is in VRAM, as 256 two-byte entries
+const PaletteAddress = 0x1FA00
+
+// Where the registers that set up each layer start
+const (
+	RegisterL0Config = 0x0D
+	RegisterL1Config = 0x14
+)
+
+// the registers of a layer, which are in the same order for both
+const (
+	layerConfig = iota
+	layerMapBase
+	layerTileBase
+	layerHScrollL
+	layerHScrollH
+	layerVScrollL
+	layerVScrollH
+)
+
+// Layer is how one of VERA's layers turns VRAM into pixels
+type Layer struct {
+	BPP    BPP
+	Bitmap bool
+
+	// in tile mode, the size of the map in tiles and of each tile in pixels
+	MapWidth, MapHeight   int
+	TileWidth, TileHeight TileSize
+	// in bitmap mode, 320 or 640 pixels, with as many rows as there are on the screen
+	BitmapWidth int
+
+	// where the map is, and where the tiles or the bitmap are
+	MapBase, TileBase uint32
+
+	// in tile mode, how far the map is scrolled, wrapping around at its edges
+	HScroll, VScroll int
+	// in 1 bpp tile mode, whether the foreground colour is a whole byte with no background
+	T256C bool
+	// in bitmap mode, added to non-zero colours in 16ths of the palette
+	PaletteOffset byte
+}
+
+// Layer decodes the registers of layer 0 or 1
+func (d *Device) Layer(n int) Layer {
+	base := RegisterL0Config
+	if n == 1 {
+		base = RegisterL1Config
+	}
+	config := d.registers[base+layerConfig]
+	tileBase := d.registers[base+layerTileBase]
+	hScrollH := d.registers[base+layerHScrollH]
+
+	l := Layer{
+		BPP:        BPP(1 << (config & 0x03)),
+		Bitmap:     config&0x04 != 0,
+		T256C:      config&0x08 != 0,
+		MapWidth:   32 << ((config >> 4) & 0x03),
+		MapHeight:  32 << (config >> 6),
+		TileWidth:  Eight << (tileBase & 0x01),
+		TileHeight: Eight << ((tileBase >> 1) & 0x01),
+		MapBase:    uint32(d.registers[base+layerMapBase]) << 9,
+		TileBase:   uint32(tileBase&0xFC) << 9,
+		HScroll:    int(hScrollH&0x0F)<<8 | int(d.registers[base+layerHScrollL]),
+		VScroll:    int(d.registers[base+layerVScrollH]&0x0F)<<8 | int(d.registers[base+layerVScrollL]),
+	}
+	if l.Bitmap {
+		l.BitmapWidth = 320 << (tileBase & 0x01)
+		l.PaletteOffset = hScrollH & 0x0F
+	}
+	return l
+}
+
+// Palette decodes the palette VERA has in VRAM
+func (d *Device) Palette() color.Palette {
+	return PaletteFromVRAM(d.VRAM[:])
+}
+
+// PaletteFromVRAM decodes the palette in a snapshot of VRAM, which has
+// 4 bits of each of red, green and blue per colour
+func PaletteFromVRAM(vram []byte) color.Palette {
+	palette := make(color.Palette, 256)
+	for i := range palette {
+		gb, r := vram[PaletteAddress+2*i], vram[PaletteAddress+2*i+1]
+		palette[i] = color.RGBA{
+			R: (r & 0x0F) * 0x11,
+			G: (gb >> 4) * 0x11,
+			B: (gb & 0x0F) * 0x11,
+			A: 0xFF,
+		}
+	}
+	return palette
+}
+
+// Render draws what a layer shows on a screen of the given size from a snapshot of VRAM, before
+// it's scaled. Transparent pixels are drawn in colour 0.
+func Render(vram []byte, layer Layer, palette color.Palette, width, height int) (*image.Paletted, error) {
+	if len(vram) != VRAMSize {
+		return nil, fmt.Errorf("expected %d bytes of VRAM, but there were %d", VRAMSize, len(vram))
+	}
+	if _, ok := ToBPP(int(layer.BPP)); !ok {
+		return nil, fmt.Errorf("bpp must be one of 1, 2, 4, or 8, but it was %d", layer.BPP)
+	}
+	// only bitmaps without a palette offset can't pick colours from anywhere in the palette
+	colours := 256
+	if layer.Bitmap && layer.PaletteOffset == 0 {
+		colours = layer.BPP.MaxColors()
+	}
+	if len(palette) < colours {
+		return nil, fmt.Errorf("expected a palette of at least %d colours, but it had %d", colours, len(palette))
+	}
+
+	img := image.NewPaletted(image.Rect(0, 0, width, height), palette)
+	if layer.Bitmap {
+		if layer.BitmapWidth != 320 && layer.BitmapWidth != 640 {
+			return nil, fmt.Errorf("bitmap width must be 320 or 640, but it was %d", layer.BitmapWidth)
+		}
+		for y := 0; y < height; y++ {
+			for x := 0; x < width && x < layer.BitmapWidth; x++ {
+				colour := pixel(vram, layer.TileBase, y*layer.BitmapWidth+x, layer.BPP)
+				if colour != 0 && layer.BPP != BPP8 {
+					colour += layer.PaletteOffset << 4
+				}
+				img.SetColorIndex(x, y, colour)
+			}
+		}
+		return img, nil
+	}
+
+	if _, ok := ToTileSize(int(layer.TileWidth)); !ok {
+		return nil, fmt.Errorf("tile width must be one of 8, 16, 32, or 64, but it was %d", layer.TileWidth)
+	}
+	if _, ok := ToTileSize(int(layer.TileHeight)); !ok {
+		return nil, fmt.Errorf("tile height must be one of 8, 16, 32, or 64, but it was %d", layer.TileHeight)
+	}
+	tw, th := int(layer.TileWidth), int(layer.TileHeight)
+	tileBytes := tw * th * int(layer.BPP) / 8
+	mapWidth, mapHeight := layer.MapWidth*tw, layer.MapHeight*th
+	if mapWidth == 0 || mapHeight == 0 {
+		return nil, fmt.Errorf("the map must be at least one tile in each direction")
+	}
+
+	for y := 0; y < height; y++ {
+		my := (y + layer.VScroll) % mapHeight
+		for x := 0; x < width; x++ {
+			mx := (x + layer.HScroll) % mapWidth
+			entry := (layer.MapBase + uint32(2*((my/th)*layer.MapWidth+mx/tw))) % VRAMSize
+			index, attributes := int(vram[entry]), vram[(entry+1)%VRAMSize]
+
+			tx, ty := mx%tw, my%th
+			if layer.BPP == BPP1 {
+				// text mode, where the attributes are the colours
+				on := pixel(vram, layer.TileBase+uint32(index*tileBytes), ty*tw+tx, BPP1) != 0
+				switch {
+				case on && layer.T256C:
+					img.SetColorIndex(x, y, attributes)
+				case on:
+					img.SetColorIndex(x, y, attributes&0x0F)
+				case !layer.T256C:
+					img.SetColorIndex(x, y, attributes>>4)
+				}
+				continue
+			}
+
+			index |= int(attributes&0x03) << 8
+			if attributes&0x04 != 0 {
+				tx = tw - 1 - tx
+			}
+			if attributes&0x08 != 0 {
+				ty = th - 1 - ty
+			}
+			colour := pixel(vram, layer.TileBase+uint32(index*tileBytes), ty*tw+tx, layer.BPP)
+			if colour != 0 && layer.BPP != BPP8 {
+				colour += attributes & 0xF0
+			}
+			img.SetColorIndex(x, y, colour)
+		}
+	}
+	return img, nil
+}
+
+// the colour of the nth pixel of packed pixel data at base, with the leftmost pixels in the high bits
+func pixel(vram []byte, base uint32, n int, bpp BPP) byte {
+	bit := n * int(bpp)
+	b := vram[(base+uint32(bit/8))%VRAMSize]
+	shift := 8 - int(bpp) - bit%8
+	return (b >> shift) & byte(bpp.MaxColors()-1)
+}
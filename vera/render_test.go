@@ -0,0 +1,136 @@
+package vera
+
+import (
+	"Sano/vera/veratest"
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+)
+
+// a palette with a different colour for every index
+func testPalette() color.Palette {
+	palette := make(color.Palette, 256)
+	for i := range palette {
+		palette[i] = color.RGBA{byte(i * 37), byte(i * 91), byte(i * 13), 0xFF}
+	}
+	return palette
+}
+
+func testImage(width, height int, bpp BPP) *image.Paletted {
+	img := image.NewPaletted(image.Rect(0, 0, width, height), testPalette()[:bpp.MaxColors()])
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetColorIndex(x, y, byte((x/3+y*5)%bpp.MaxColors()))
+		}
+	}
+	return img
+}
+
+func sameIndices(t *testing.T, got, expected *image.Paletted) {
+	t.Helper()
+	for y := 0; y < expected.Rect.Dy(); y++ {
+		for x := 0; x < expected.Rect.Dx(); x++ {
+			if got.ColorIndexAt(x, y) != expected.ColorIndexAt(x, y) {
+				t.Fatalf("(%d, %d) is colour %d, but should be %d", x, y, got.ColorIndexAt(x, y), expected.ColorIndexAt(x, y))
+			}
+		}
+	}
+}
+
+func TestRenderExportedBitmaps(t *testing.T) {
+	for _, bpp := range []BPP{BPP1, BPP2, BPP4, BPP8} {
+		img := testImage(320, 4, bpp)
+		var buf bytes.Buffer
+		if err := ExportBitmap(img, bpp, &buf); err != nil {
+			t.Fatal(err)
+		}
+		var vram [VRAMSize]byte
+		copy(vram[0x4000:], buf.Bytes())
+
+		layer := Layer{BPP: bpp, Bitmap: true, BitmapWidth: 320, TileBase: 0x4000}
+		got, err := Render(vram[:], layer, img.Palette, 320, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sameIndices(t, got, img)
+	}
+}
+
+func TestRenderExportedTiles(t *testing.T) {
+	for _, bpp := range []BPP{BPP2, BPP4, BPP8} {
+		for _, size := range []TileSize{Eight, Sixteen} {
+			img := testImage(2*int(size), 2*int(size), bpp)
+			var buf bytes.Buffer
+			if err := ExportTile(img, bpp, size, size, &buf); err != nil {
+				t.Fatal(err)
+			}
+			var vram [VRAMSize]byte
+			// without the load address
+			copy(vram[0x8000:], buf.Bytes()[2:])
+			// tiles 0 and 1 on the first row of the map, and 2 and 3 on the second
+			for i, entry := range []int{0, 1, 32, 33} {
+				vram[0x1000+2*entry] = byte(i)
+			}
+
+			layer := Layer{
+				BPP:       bpp,
+				MapWidth:  32,
+				MapHeight: 32,
+				TileWidth: size, TileHeight: size,
+				MapBase:  0x1000,
+				TileBase: 0x8000,
+			}
+			got, err := Render(vram[:], layer, testPalette(), 2*int(size), 2*int(size))
+			if err != nil {
+				t.Fatal(err)
+			}
+			sameIndices(t, got, img)
+		}
+	}
+}
+
+func TestRenderTextMode(t *testing.T) {
+	d := NewDevice()
+	// a checkerboard character and a solid one
+	for i := 0; i < 8; i++ {
+		d.VRAM[0xF800+8+i] = 0xAA >> (i % 2)
+		d.VRAM[0xF800+16+i] = 0xFF
+	}
+	// white on blue, red on black, and white on red
+	copy(d.VRAM[0xB000:], []byte{1, 0x61, 2, 0x02, 1, 0x21, 2, 0x00})
+	copy(d.VRAM[PaletteAddress:], []byte{0x00, 0x00, 0xFF, 0x0F, 0x00, 0x0F, 0, 0, 0, 0, 0, 0, 0x0F, 0x00})
+
+	// a 128x64 map of 8x8 tiles at $B000 with tiles at $F800, scrolled left by 4 pixels
+	layer := RegisterL1Config
+	d.registers[layer+layerConfig] = 0x60
+	d.registers[layer+layerMapBase] = 0xB000 >> 9
+	d.registers[layer+layerTileBase] = 0xF800 >> 9
+	d.registers[layer+layerHScrollL] = 4
+
+	img, err := Render(d.VRAM[:], d.Layer(1), d.Palette(), 24, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	veratest.CompareGolden(t, img, "testdata/text.png")
+}
+
+func TestRenderFlippedTiles(t *testing.T) {
+	var vram [VRAMSize]byte
+	// a 4bpp tile with a diagonal line and a dot in its top right corner
+	tile := vram[0x2000+32:]
+	for i := 0; i < 8; i++ {
+		tile[i*4+i/2] |= 0x1 << (4 * (1 - i%2))
+	}
+	tile[3] |= 0x02
+	// the tile four ways round, with the last one in the second half of the palette
+	copy(vram[0:], []byte{1, 0x00, 1, 0x04, 1, 0x08, 1, 0x8C})
+	palette := testPalette()
+
+	layer := Layer{BPP: BPP4, MapWidth: 32, MapHeight: 32, TileWidth: Eight, TileHeight: Eight, TileBase: 0x2000}
+	img, err := Render(vram[:], layer, palette, 32, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	veratest.CompareGolden(t, img, "testdata/flipped.png")
+}
@@ -0,0 +1,86 @@
+// Package veratest helps test graphics code by comparing what it draws against golden images
+package veratest
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// UpdateEnv is the environment variable that makes CompareGolden write the
+// images it's given as the new golden images instead of comparing against them
+const UpdateEnv = "SANO_UPDATE_GOLDEN"
+
+// CompareGolden fails the test if img doesn't have the same colours as the PNG at path.
+// When it doesn't, what was drawn is written next to the golden image with .actual.png
+// on the end of its name, to be looked at or copied over it.
+func CompareGolden(t testing.TB, img image.Image, path string) {
+	t.Helper()
+
+	if os.Getenv(UpdateEnv) != "" {
+		if err := writePNG(path, img); err != nil {
+			t.Fatalf("failed to update golden image: %s", err)
+		}
+		return
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open golden image (set %s=1 to create it): %s", UpdateEnv, err)
+	}
+	defer file.Close()
+	golden, err := png.Decode(file)
+	if err != nil {
+		t.Fatalf("failed to decode golden image: %s", err)
+	}
+
+	if msg, ok := compare(img, golden); !ok {
+		actual := path + ".actual.png"
+		if err := writePNG(actual, img); err != nil {
+			t.Errorf("failed to write %s: %s", actual, err)
+		}
+		t.Errorf("%s doesn't match %s: %s", actual, filepath.Base(path), msg)
+	}
+}
+
+// compares two images pixel by pixel, describing the first difference
+func compare(img, golden image.Image) (string, bool) {
+	if img.Bounds().Size() != golden.Bounds().Size() {
+		return fmt.Sprintf("it is %v, but should be %v", img.Bounds().Size(), golden.Bounds().Size()), false
+	}
+
+	differences := 0
+	var first image.Point
+	size := img.Bounds().Size()
+	for y := 0; y < size.Y; y++ {
+		for x := 0; x < size.X; x++ {
+			r1, g1, b1, a1 := img.At(img.Bounds().Min.X+x, img.Bounds().Min.Y+y).RGBA()
+			r2, g2, b2, a2 := golden.At(golden.Bounds().Min.X+x, golden.Bounds().Min.Y+y).RGBA()
+			if r1 != r2 || g1 != g2 || b1 != b2 || a1 != a2 {
+				if differences == 0 {
+					first = image.Pt(x, y)
+				}
+				differences++
+			}
+		}
+	}
+	if differences > 0 {
+		return fmt.Sprintf("%d pixels are different, starting at %v", differences, first), false
+	}
+	return "", true
+}
+
+func writePNG(path string, img image.Image) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(file, img); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
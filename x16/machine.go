@@ -0,0 +1,120 @@
+package x16
+
+import "Sano/vera"
+
+// The Commander X16's memory map
+const (
+	// writing these picks which bank of RAM and ROM is mapped in
+	RAMBankRegister = 0x0000
+	ROMBankRegister = 0x0001
+
+	IOStart        = 0x9F00
+	VERAStart      = 0x9F20
+	VERAEnd        = VERAStart + vera.RegisterCount
+	IOEnd          = 0xA000
+	BankedRAMStart = 0xA000
+	ROMStart       = 0xC000
+
+	RAMBankSize = 0x2000
+	ROMBankSize = 0x4000
+	ROMBanks    = 32
+	// the X16 comes with 512K of banked RAM, and can have up to 2MB
+	DefaultRAMBanks = 64
+)
+
+// the opcode of RTS
+const rts = 0x60
+
+// Machine is a bus with the X16's memory map, for running code on a simulated CPU
+// that expects to be on one. Banked RAM and ROM are switched by writing to $00 and $01,
+// VERA is at $9F20, and the rest of the I/O area holds whatever was written to it.
+type Machine struct {
+	// everything below the I/O area that isn't banked, apart from the bank registers
+	RAM       [IOStart]byte
+	BankedRAM [][RAMBankSize]byte
+	// every ROM bank starts out as nothing but RTS instructions, so that code that
+	// calls into the KERNAL carries on as if the call did nothing
+	ROM     [ROMBanks][ROMBankSize]byte
+	RAMBank byte
+	ROMBank byte
+
+	VERA *vera.Device
+
+	io [IOEnd - IOStart]byte
+}
+
+// New makes a machine with the given number of 8K banks of RAM
+func New(ramBanks int) *Machine {
+	m := &Machine{
+		BankedRAM: make([][RAMBankSize]byte, ramBanks),
+		VERA:      vera.NewDevice(),
+	}
+	for bank := range m.ROM {
+		for i := range m.ROM[bank] {
+			m.ROM[bank][i] = rts
+		}
+	}
+	return m
+}
+
+// LoadROM replaces the contents of a ROM bank, leaving the rest of it as it was if data is shorter
+func (m *Machine) LoadROM(bank int, data []byte) {
+	copy(m.ROM[bank][:], data)
+}
+
+// VideoRAM is VERA's video memory
+func (m *Machine) VideoRAM() []byte {
+	return m.VERA.VRAM[:]
+}
+
+// the RAM bank that's mapped in, which wraps around if there are fewer banks than the register can pick
+func (m *Machine) bank() *[RAMBankSize]byte {
+	if len(m.BankedRAM) == 0 {
+		return nil
+	}
+	return &m.BankedRAM[int(m.RAMBank)%len(m.BankedRAM)]
+}
+
+func (m *Machine) Read(address uint16) byte {
+	switch {
+	case address == RAMBankRegister:
+		return m.RAMBank
+	case address == ROMBankRegister:
+		return m.ROMBank
+	case address < IOStart:
+		return m.RAM[address]
+	case address >= VERAStart && address < VERAEnd:
+		return m.VERA.Read(address - VERAStart)
+	case address < IOEnd:
+		return m.io[address-IOStart]
+	case address < ROMStart:
+		bank := m.bank()
+		if bank == nil {
+			return 0
+		}
+		return bank[address-BankedRAMStart]
+	default:
+		return m.ROM[int(m.ROMBank)%ROMBanks][address-ROMStart]
+	}
+}
+
+func (m *Machine) Write(address uint16, value byte) {
+	switch {
+	case address == RAMBankRegister:
+		m.RAMBank = value
+	case address == ROMBankRegister:
+		m.ROMBank = value
+	case address < IOStart:
+		m.RAM[address] = value
+	case address >= VERAStart && address < VERAEnd:
+		m.VERA.Write(address-VERAStart, value)
+	case address < IOEnd:
+		m.io[address-IOStart] = value
+	case address < ROMStart:
+		if bank := m.bank(); bank != nil {
+			bank[address-BankedRAMStart] = value
+		}
+	default:
+		// ROM can't be written to
+	}
+}
@@ -0,0 +1,41 @@
+package x16
+
+import "testing"
+
+func TestBanking(t *testing.T) {
+	m := New(DefaultRAMBanks)
+	m.Write(RAMBankRegister, 1)
+	m.Write(0xA000, 0x11)
+	m.Write(RAMBankRegister, 2)
+	m.Write(0xA000, 0x22)
+	if m.Read(0xA000) != 0x22 || m.Read(RAMBankRegister) != 2 {
+		t.Errorf("bank 2 has $%02X", m.Read(0xA000))
+	}
+	m.Write(RAMBankRegister, 1)
+	if m.Read(0xA000) != 0x11 {
+		t.Errorf("bank 1 has $%02X", m.Read(0xA000))
+	}
+	// there are only 64 banks
+	m.Write(RAMBankRegister, 65)
+	if m.Read(0xA000) != 0x11 {
+		t.Errorf("bank 65 has $%02X", m.Read(0xA000))
+	}
+
+	m.LoadROM(4, []byte{0x42})
+	m.Write(ROMBankRegister, 4)
+	m.Write(0xC000, 0x00)
+	if m.Read(0xC000) != 0x42 || m.Read(0xC001) != rts {
+		t.Errorf("ROM bank 4 starts $%02X $%02X", m.Read(0xC000), m.Read(0xC001))
+	}
+}
+
+func TestVERAIsMappedIn(t *testing.T) {
+	m := New(DefaultRAMBanks)
+	m.Write(0x9F20, 0x34)
+	m.Write(0x9F21, 0x12)
+	m.Write(0x9F22, 0x10)
+	m.Write(0x9F23, 0x56)
+	if m.VERA.VRAM[0x1234] != 0x56 || m.Read(0x9F20) != 0x35 {
+		t.Errorf("VRAM has $%02X, and the address is $%02X", m.VERA.VRAM[0x1234], m.Read(0x9F20))
+	}
+}